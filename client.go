@@ -1,40 +1,137 @@
 package scimsdk
 
 import (
-	"strings"
+	"log/slog"
+	"net/http"
+	"time"
 
 	"github.com/strongdm/scimsdk/internal/api"
 	"github.com/strongdm/scimsdk/internal/module"
 	"github.com/strongdm/scimsdk/internal/service"
+	"go.opentelemetry.io/otel/trace"
 )
 
 type Client interface {
 	Users() UserModule
 	Groups() GroupModule
+	Discovery() DiscoveryModule
+	Bulk() BulkModule
 	GetProvidedURL() string
 }
 
 type ClientOptions struct {
 	APIUrl string
+	// HTTPClient is the http client used to execute every request. When it's
+	// not set, a default client that reuses connections is used.
+	HTTPClient *http.Client
+	// Transport is the http transport used by the default http client (e.g. to
+	// set a proxy or custom TLS roots). It's ignored when HTTPClient is set.
+	Transport http.RoundTripper
+	// RetryPolicy defines how the failed requests are retried. When it's not
+	// set, the idempotent requests are retried up to 3 times.
+	RetryPolicy *RetryPolicy
+	// TokenSource provides the token for every request. When it's set, the
+	// admin token passed to NewClient is ignored
+	TokenSource TokenSource
+	// Middlewares wrap every outgoing request and incoming response (e.g. to
+	// add headers or audit the mutations). The first one is the outermost
+	Middlewares []Middleware
+	// Logger receives a log entry for each request attempt and iterator page
+	// fetch. The Authorization header is never logged
+	Logger *slog.Logger
+	// LogBodies adds the request and response bodies to the debug logs
+	LogBodies bool
+	// RedactedFields are the JSON attributes and query params whose values are
+	// redacted in the logs. When it's nil, DefaultRedactedFields is used
+	RedactedFields []string
+	// TracerProvider enables the OpenTelemetry spans of each module method and
+	// http attempt, propagating the W3C trace context in the requests
+	TracerProvider trace.TracerProvider
+	// Metrics records the request counts, latencies, retries and iterator
+	// pages. See the metrics/prometheus package for a Prometheus adapter
+	Metrics Metrics
+	// MaxResponseSize limits the decoded size of a response body in bytes.
+	// When it's not set, the responses are limited to 32MiB
+	MaxResponseSize int64
+	// RateLimit throttles the requests of every module obtained from the
+	// client, pausing them when the server reports its rate limit is reached
+	RateLimit *RateLimit
+	// MaxSortedResources limits the resources fetched to sort a list on the
+	// client when the server doesn't support sorting. When it's not set, the
+	// lists with more than 10000 resources fail instead of being sorted
+	MaxSortedResources int
+}
+
+// DefaultRedactedFields are the PII attributes redacted in the logs by default.
+var DefaultRedactedFields = api.DefaultRedactedFields
+
+// RetryPolicy defines how the requests that failed because of a transport
+// error or a transient status (429, 502, 503 and 504) are retried, using an
+// exponential backoff with jitter or the Retry-After header sent by the server.
+// The zero values are replaced by the defaults.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one.
+	// Set it to 1 to disable the retries
+	MaxAttempts int
+	// InitialBackoff is the base wait time between attempts (default 500ms)
+	InitialBackoff time.Duration
+	// MaxBackoff is the maximum wait time between attempts (default 30s)
+	MaxBackoff time.Duration
+	// RetryableMethods are the http methods that can be retried. By default
+	// only GET, PUT and DELETE are retried, add POST and PATCH to opt them in.
+	// The searches (POST /.search) are retried as GET requests
+	RetryableMethods []string
+}
+
+// RateLimit defines the client-side token buckets. A zero rate disables the
+// corresponding bucket, and the read and write budgets apply on top of the
+// shared one.
+type RateLimit struct {
+	// RequestsPerSecond is the rate shared by every request
+	RequestsPerSecond float64
+	// Burst is the number of requests that can be sent at once (default 1)
+	Burst int
+	// ReadRequestsPerSecond is the rate of the GET requests
+	ReadRequestsPerSecond float64
+	// ReadBurst is the number of GET requests that can be sent at once (default 1)
+	ReadBurst int
+	// WriteRequestsPerSecond is the rate of the POST, PUT, PATCH and DELETE requests
+	WriteRequestsPerSecond float64
+	// WriteBurst is the number of write requests that can be sent at once (default 1)
+	WriteBurst int
+	// MaxPause limits how long every request is paused when the server asks
+	// to wait with the Retry-After or rate limit reset headers (default 30s)
+	MaxPause time.Duration
 }
 
 type clientImpl struct {
-	token   string
-	options *ClientOptions
+	tokenSource           TokenSource
+	options               *ClientOptions
+	api                   api.API
+	instrumentation       module.Instrumentation
+	serviceProviderConfig *module.ServiceProviderConfigLoader
 }
 
 func NewClient(adminToken string, opts *ClientOptions) Client {
-	trimmedToken := strings.TrimSpace(adminToken)
-	client := &clientImpl{trimmedToken, opts}
+	client := &clientImpl{tokenSource: getTokenSource(adminToken, opts), options: opts, api: api.NewAPI(getAPIConfig(opts)), instrumentation: getInstrumentation(opts)}
+	client.serviceProviderConfig = module.NewServiceProviderConfigLoader(service.NewDiscoveryService(client.api, client.tokenSource), client.GetProvidedURL(), getMaxSortedResources(opts))
 	return client
 }
 
 func (client *clientImpl) Users() UserModule {
-	return module.NewUserModule(service.NewUserService(api.NewAPI(), client.getToken()), client.GetProvidedURL())
+	return module.NewUserModule(service.NewUserService(client.api, client.tokenSource), client.GetProvidedURL(), client.instrumentation, client.serviceProviderConfig)
 }
 
 func (client *clientImpl) Groups() GroupModule {
-	return module.NewGroupModule(service.NewGroupService(api.NewAPI(), client.getToken()), client.GetProvidedURL())
+	return module.NewGroupModule(service.NewGroupService(client.api, client.tokenSource), client.GetProvidedURL(), client.instrumentation, client.serviceProviderConfig)
+}
+
+func (client *clientImpl) Discovery() DiscoveryModule {
+	return module.NewDiscoveryModule(service.NewDiscoveryService(client.api, client.tokenSource), client.GetProvidedURL(), client.instrumentation, client.serviceProviderConfig)
+}
+
+func (client *clientImpl) Bulk() BulkModule {
+	return module.NewBulkModule(service.NewBulkService(client.api, client.tokenSource), client.GetProvidedURL(), client.instrumentation, client.serviceProviderConfig)
 }
 
 func (client *clientImpl) GetProvidedURL() string {
@@ -44,6 +141,77 @@ func (client *clientImpl) GetProvidedURL() string {
 	return ""
 }
 
-func (client *clientImpl) getToken() string {
-	return client.token
+func getAPIConfig(opts *ClientOptions) api.Config {
+	config := api.Config{
+		HTTPClient:  getHTTPClient(opts),
+		RetryPolicy: getRetryPolicy(opts),
+	}
+	if opts != nil {
+		config.Middlewares = opts.Middlewares
+		config.Logging = &api.LogConfig{
+			Logger:         opts.Logger,
+			LogBodies:      opts.LogBodies,
+			RedactedFields: opts.RedactedFields,
+		}
+		config.TracerProvider = opts.TracerProvider
+		config.Metrics = opts.Metrics
+		config.MaxResponseSize = opts.MaxResponseSize
+		config.RateLimit = getRateLimit(opts)
+	}
+	return config
+}
+
+func getInstrumentation(opts *ClientOptions) module.Instrumentation {
+	if opts == nil {
+		return module.Instrumentation{Tracer: api.NewTracer(nil), Metrics: api.NewNopMetrics()}
+	}
+	instrumentation := module.Instrumentation{
+		Logger:  opts.Logger,
+		Tracer:  api.NewTracer(opts.TracerProvider),
+		Metrics: opts.Metrics,
+	}
+	if instrumentation.Metrics == nil {
+		instrumentation.Metrics = api.NewNopMetrics()
+	}
+	return instrumentation
+}
+
+func getHTTPClient(opts *ClientOptions) *http.Client {
+	if opts == nil {
+		return api.NewHTTPClient(nil)
+	}
+	if opts.HTTPClient != nil {
+		return opts.HTTPClient
+	}
+	return api.NewHTTPClient(opts.Transport)
+}
+
+func getRetryPolicy(opts *ClientOptions) *api.RetryPolicy {
+	if opts == nil || opts.RetryPolicy == nil {
+		return api.DefaultRetryPolicy()
+	}
+	policy := opts.RetryPolicy
+	return api.NewRetryPolicy(policy.MaxAttempts, policy.InitialBackoff, policy.MaxBackoff, policy.RetryableMethods)
+}
+
+func getMaxSortedResources(opts *ClientOptions) int {
+	if opts == nil {
+		return 0
+	}
+	return opts.MaxSortedResources
+}
+
+func getRateLimit(opts *ClientOptions) *api.RateLimit {
+	if opts.RateLimit == nil {
+		return nil
+	}
+	rateLimit := api.RateLimit(*opts.RateLimit)
+	return &rateLimit
+}
+
+func getTokenSource(adminToken string, opts *ClientOptions) TokenSource {
+	if opts != nil && opts.TokenSource != nil {
+		return opts.TokenSource
+	}
+	return NewStaticTokenSource(adminToken)
 }
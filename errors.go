@@ -0,0 +1,34 @@
+package scimsdk
+
+import "github.com/strongdm/scimsdk/internal/api"
+
+// Error is returned when the SCIM API responds with an error status. Use
+// errors.As to inspect the status code, the scimType and the raw body, or
+// errors.Is with the sentinel errors below to check the kind of failure.
+type Error = api.Error
+
+var (
+	// ErrBadRequest matches the errors with status 400
+	ErrBadRequest = api.ErrBadRequest
+	// ErrUnauthorized matches the errors with status 401 (e.g. invalid token)
+	ErrUnauthorized = api.ErrUnauthorized
+	// ErrForbidden matches the errors with status 403
+	ErrForbidden = api.ErrForbidden
+	// ErrNotFound matches the errors with status 404
+	ErrNotFound = api.ErrNotFound
+	// ErrConflict matches the errors with status 409 (e.g. user already exists)
+	ErrConflict = api.ErrConflict
+	// ErrPreconditionFailed matches the errors with status 412, returned when
+	// the resource was changed since the version passed in IfMatch
+	ErrPreconditionFailed = api.ErrPreconditionFailed
+	// ErrNotModified matches the status 304, returned by FindWithOptions when
+	// the resource still has the version passed in IfNoneMatch
+	ErrNotModified = api.ErrNotModified
+	// ErrRateLimited matches the errors with status 429
+	ErrRateLimited = api.ErrRateLimited
+	// ErrServer matches the errors with status 5xx
+	ErrServer = api.ErrServer
+	// ErrResponseTooLarge is returned when a response body exceeds
+	// ClientOptions.MaxResponseSize
+	ErrResponseTooLarge = api.ErrResponseTooLarge
+)
@@ -0,0 +1,256 @@
+// Package filter builds, parses and evaluates SCIM filter expressions (RFC
+// 7644 section 3.4.2.2).
+//
+// The values are always encoded as JSON literals, so the expressions can be
+// safely built from user input:
+//
+//	filter.And(
+//		filter.Sw("userName", search),
+//		filter.Values("emails", filter.Eq("type", "work")),
+//	).String() // userName sw "..." and emails[type eq "work"]
+//
+// The attribute paths are written as they are passed, so they must not come
+// from user input.
+package filter
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Operator is a SCIM filter operator.
+type Operator string
+
+const (
+	OperatorEq  Operator = "eq"
+	OperatorNe  Operator = "ne"
+	OperatorCo  Operator = "co"
+	OperatorSw  Operator = "sw"
+	OperatorEw  Operator = "ew"
+	OperatorPr  Operator = "pr"
+	OperatorGt  Operator = "gt"
+	OperatorGe  Operator = "ge"
+	OperatorLt  Operator = "lt"
+	OperatorLe  Operator = "le"
+	OperatorAnd Operator = "and"
+	OperatorOr  Operator = "or"
+)
+
+const (
+	precedenceOr = iota + 1
+	precedenceAnd
+	precedenceUnary
+)
+
+// Filter is a node of a SCIM filter expression. String returns the
+// expression in the SCIM filter syntax, adding the parentheses required by
+// the operators precedence.
+type Filter interface {
+	String() string
+	precedence() int
+	match(resource reflect.Value) bool
+}
+
+// Comparison compares an attribute with a value, or checks if it's present
+// when the operator is pr.
+type Comparison struct {
+	Attribute string
+	Operator  Operator
+	// Value is a string, bool, number (including json.Number), time.Time or
+	// nil. It's ignored by pr
+	Value interface{}
+}
+
+func (comparison *Comparison) String() string {
+	if comparison.Operator == OperatorPr {
+		return fmt.Sprintf("%s %s", comparison.Attribute, comparison.Operator)
+	}
+	return fmt.Sprintf("%s %s %s", comparison.Attribute, comparison.Operator, formatValue(comparison.Value))
+}
+
+func (comparison *Comparison) precedence() int {
+	return precedenceUnary
+}
+
+// Logical joins two filters with the and/or operators.
+type Logical struct {
+	Operator Operator
+	Left     Filter
+	Right    Filter
+}
+
+func (logical *Logical) String() string {
+	return fmt.Sprintf("%s %s %s", group(logical.Left, logical.precedence()), logical.Operator, group(logical.Right, logical.precedence()))
+}
+
+func (logical *Logical) precedence() int {
+	if logical.Operator == OperatorOr {
+		return precedenceOr
+	}
+	return precedenceAnd
+}
+
+// Negation negates a filter.
+type Negation struct {
+	Filter Filter
+}
+
+func (negation *Negation) String() string {
+	return fmt.Sprintf("not (%s)", negation.Filter)
+}
+
+func (negation *Negation) precedence() int {
+	return precedenceUnary
+}
+
+// ValuePath filters the values of a multi-valued complex attribute, e.g.
+// emails[type eq "work"].
+type ValuePath struct {
+	Attribute string
+	Filter    Filter
+}
+
+func (valuePath *ValuePath) String() string {
+	return fmt.Sprintf("%s[%s]", valuePath.Attribute, valuePath.Filter)
+}
+
+func (valuePath *ValuePath) precedence() int {
+	return precedenceUnary
+}
+
+// ValuePathComparison compares a sub-attribute of the values filtered by a
+// value path, e.g. emails[type eq "work"].value co "@example.com".
+type ValuePathComparison struct {
+	ValuePath *ValuePath
+	// Comparison compares the sub-attribute, which is its Attribute
+	Comparison *Comparison
+}
+
+func (comparison *ValuePathComparison) String() string {
+	return fmt.Sprintf("%s.%s", comparison.ValuePath, comparison.Comparison)
+}
+
+func (comparison *ValuePathComparison) precedence() int {
+	return precedenceUnary
+}
+
+func Eq(attribute string, value interface{}) Filter {
+	return &Comparison{attribute, OperatorEq, value}
+}
+
+func Ne(attribute string, value interface{}) Filter {
+	return &Comparison{attribute, OperatorNe, value}
+}
+
+func Co(attribute string, value interface{}) Filter {
+	return &Comparison{attribute, OperatorCo, value}
+}
+
+func Sw(attribute string, value interface{}) Filter {
+	return &Comparison{attribute, OperatorSw, value}
+}
+
+func Ew(attribute string, value interface{}) Filter {
+	return &Comparison{attribute, OperatorEw, value}
+}
+
+func Pr(attribute string) Filter {
+	return &Comparison{attribute, OperatorPr, nil}
+}
+
+func Gt(attribute string, value interface{}) Filter {
+	return &Comparison{attribute, OperatorGt, value}
+}
+
+func Ge(attribute string, value interface{}) Filter {
+	return &Comparison{attribute, OperatorGe, value}
+}
+
+func Lt(attribute string, value interface{}) Filter {
+	return &Comparison{attribute, OperatorLt, value}
+}
+
+func Le(attribute string, value interface{}) Filter {
+	return &Comparison{attribute, OperatorLe, value}
+}
+
+// And joins the filters with the and operator, ignoring the nil ones. It
+// returns nil when there are no filters.
+func And(filters ...Filter) Filter {
+	return join(OperatorAnd, filters)
+}
+
+// Or joins the filters with the or operator, ignoring the nil ones. It
+// returns nil when there are no filters.
+func Or(filters ...Filter) Filter {
+	return join(OperatorOr, filters)
+}
+
+func Not(filter Filter) Filter {
+	return &Negation{filter}
+}
+
+// Values creates a value path filter, e.g. Values("emails", Eq("type", "work"))
+// is emails[type eq "work"].
+func Values(attribute string, filter Filter) Filter {
+	return &ValuePath{attribute, filter}
+}
+
+func join(operator Operator, filters []Filter) Filter {
+	var result Filter
+	for _, filter := range filters {
+		if filter == nil {
+			continue
+		}
+		if result == nil {
+			result = filter
+		} else {
+			result = &Logical{operator, result, filter}
+		}
+	}
+	return result
+}
+
+// group wraps the filter in parentheses when it binds weaker than its parent.
+func group(filter Filter, parentPrecedence int) string {
+	if filter.precedence() < parentPrecedence {
+		return fmt.Sprintf("(%s)", filter)
+	}
+	return filter.String()
+}
+
+// formatValue encodes the value as a JSON literal, escaping the quotes and
+// control characters of the strings.
+func formatValue(value interface{}) string {
+	switch typedValue := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return strconv.FormatBool(typedValue)
+	case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		return fmt.Sprint(typedValue)
+	case float32:
+		return strconv.FormatFloat(float64(typedValue), 'g', -1, 32)
+	case float64:
+		return strconv.FormatFloat(typedValue, 'g', -1, 64)
+	case json.Number:
+		return typedValue.String()
+	case time.Time:
+		return formatString(typedValue.UTC().Format(time.RFC3339Nano))
+	case string:
+		return formatString(typedValue)
+	}
+	return formatString(fmt.Sprint(value))
+}
+
+func formatString(value string) string {
+	buff := &bytes.Buffer{}
+	encoder := json.NewEncoder(buff)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+	return string(bytes.TrimSuffix(buff.Bytes(), []byte("\n")))
+}
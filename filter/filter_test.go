@@ -0,0 +1,56 @@
+package filter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilter(t *testing.T) {
+	t.Run("should format every comparison operator", func(t *testing.T) {
+		assertT := assert.New(t)
+
+		assertT.Equal(`userName eq "john"`, Eq("userName", "john").String())
+		assertT.Equal(`userName ne "john"`, Ne("userName", "john").String())
+		assertT.Equal(`userName co "john"`, Co("userName", "john").String())
+		assertT.Equal(`userName sw "john"`, Sw("userName", "john").String())
+		assertT.Equal(`userName ew "john"`, Ew("userName", "john").String())
+		assertT.Equal(`title pr`, Pr("title").String())
+		assertT.Equal(`meta.version gt 1`, Gt("meta.version", 1).String())
+		assertT.Equal(`meta.version ge 1.5`, Ge("meta.version", 1.5).String())
+		assertT.Equal(`active lt true`, Lt("active", true).String())
+		assertT.Equal(`meta.lastModified le "2024-01-02T03:04:05Z"`, Le("meta.lastModified", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)).String())
+		assertT.Equal(`manager eq null`, Eq("manager", nil).String())
+	})
+
+	t.Run("should escape the string values", func(t *testing.T) {
+		value := `x" or userName pr or userName eq "\y` + "\n<>"
+
+		assert.Equal(t, `userName eq "x\" or userName pr or userName eq \"\\y\n<>"`, Eq("userName", value).String())
+	})
+
+	t.Run("should group the expressions according to the operators precedence", func(t *testing.T) {
+		expression := And(
+			Or(Eq("userType", "Employee"), Eq("userType", "Contractor")),
+			Not(And(Pr("title"), Sw("title", "Dr"))),
+			Values("emails", And(Eq("type", "work"), Co("value", "@example.com"))),
+		)
+
+		assert.Equal(t, `(userType eq "Employee" or userType eq "Contractor") and not (title pr and title sw "Dr") and emails[type eq "work" and value co "@example.com"]`, expression.String())
+	})
+
+	t.Run("should not group the and expressions inside an or expression", func(t *testing.T) {
+		expression := Or(And(Eq("a", 1), Eq("b", 2)), Eq("c", 3))
+
+		assert.Equal(t, `a eq 1 and b eq 2 or c eq 3`, expression.String())
+	})
+
+	t.Run("should ignore the nil filters", func(t *testing.T) {
+		assertT := assert.New(t)
+
+		assertT.Nil(And())
+		assertT.Nil(Or(nil, nil))
+		assertT.Equal(`a eq 1`, And(nil, Eq("a", 1), nil).String())
+	})
+}
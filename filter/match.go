@@ -0,0 +1,174 @@
+package filter
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/strongdm/scimsdk/internal/attributes"
+)
+
+// Match reports whether the resource matches the filter, so the same filter
+// sent to the server can be applied to cached or exported resources. The
+// resource is a struct such as models.User or models.Group, or a map of
+// attributes. The attributes are resolved ignoring the case from the scim
+// tag or the name of the exported fields, and the schema URI prefix of the
+// attribute paths is ignored.
+//
+// As in the SCIM servers, a multi-valued attribute matches when any of its
+// values matches, and the strings are compared ignoring the case.
+func Match(filter Filter, resource interface{}) bool {
+	return filter.match(reflect.ValueOf(resource))
+}
+
+func (comparison *Comparison) match(resource reflect.Value) bool {
+	values := attributes.Resolve(resource, comparison.Attribute)
+	if comparison.Operator == OperatorPr || comparison.Value == nil {
+		present := false
+		for _, value := range values {
+			present = present || attributes.IsPresent(value)
+		}
+		switch comparison.Operator {
+		case OperatorPr, OperatorNe:
+			return present
+		case OperatorEq:
+			return !present
+		}
+		return false
+	}
+	for _, value := range values {
+		if attributes.IsComplex(value) {
+			value = attributes.Get(value, "value")
+		}
+		if value = attributes.Indirect(value); value.IsValid() && compare(value, comparison.Operator, comparison.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (logical *Logical) match(resource reflect.Value) bool {
+	if logical.Operator == OperatorOr {
+		return logical.Left.match(resource) || logical.Right.match(resource)
+	}
+	return logical.Left.match(resource) && logical.Right.match(resource)
+}
+
+func (negation *Negation) match(resource reflect.Value) bool {
+	return !negation.Filter.match(resource)
+}
+
+func (valuePath *ValuePath) match(resource reflect.Value) bool {
+	for _, value := range attributes.Resolve(resource, valuePath.Attribute) {
+		if valuePath.Filter.match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func (comparison *ValuePathComparison) match(resource reflect.Value) bool {
+	for _, value := range attributes.Resolve(resource, comparison.ValuePath.Attribute) {
+		if comparison.ValuePath.Filter.match(value) && comparison.Comparison.match(value) {
+			return true
+		}
+	}
+	return false
+}
+
+func compare(value reflect.Value, operator Operator, filterValue interface{}) bool {
+	switch typedFilterValue := filterValue.(type) {
+	case string:
+		if value.Type() == attributes.TimeType {
+			date, err := time.Parse(time.RFC3339Nano, typedFilterValue)
+			return err == nil && compareTimes(value.Interface().(time.Time), date, operator)
+		}
+		if value.Kind() != reflect.String {
+			return false
+		}
+		return compareStrings(strings.ToLower(value.String()), strings.ToLower(typedFilterValue), operator)
+	case bool:
+		if value.Kind() != reflect.Bool {
+			return false
+		}
+		return operator == OperatorEq && value.Bool() == typedFilterValue || operator == OperatorNe && value.Bool() != typedFilterValue
+	case time.Time:
+		return value.Type() == attributes.TimeType && compareTimes(value.Interface().(time.Time), typedFilterValue, operator)
+	}
+	number, ok := toFloat(reflect.ValueOf(filterValue))
+	if !ok {
+		return false
+	}
+	valueNumber, ok := toFloat(value)
+	return ok && compareOrder(compareFloats(valueNumber, number), operator)
+}
+
+func compareStrings(value, filterValue string, operator Operator) bool {
+	switch operator {
+	case OperatorCo:
+		return strings.Contains(value, filterValue)
+	case OperatorSw:
+		return strings.HasPrefix(value, filterValue)
+	case OperatorEw:
+		return strings.HasSuffix(value, filterValue)
+	}
+	return compareOrder(strings.Compare(value, filterValue), operator)
+}
+
+func compareTimes(value, filterValue time.Time, operator Operator) bool {
+	result := 0
+	if value.Before(filterValue) {
+		result = -1
+	} else if value.After(filterValue) {
+		result = 1
+	}
+	return compareOrder(result, operator)
+}
+
+func compareFloats(value, filterValue float64) int {
+	if value < filterValue {
+		return -1
+	} else if value > filterValue {
+		return 1
+	}
+	return 0
+}
+
+// compareOrder applies the operator to the result of a three-way comparison.
+func compareOrder(result int, operator Operator) bool {
+	switch operator {
+	case OperatorEq:
+		return result == 0
+	case OperatorNe:
+		return result != 0
+	case OperatorGt:
+		return result > 0
+	case OperatorGe:
+		return result >= 0
+	case OperatorLt:
+		return result < 0
+	case OperatorLe:
+		return result <= 0
+	}
+	return false
+}
+
+func toFloat(value reflect.Value) (float64, bool) {
+	if !value.IsValid() {
+		return 0, false
+	}
+	if number, ok := value.Interface().(json.Number); ok {
+		float, err := number.Float64()
+		return float, err == nil
+	}
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(value.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(value.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return value.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,78 @@
+package filter_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strongdm/scimsdk/filter"
+	"github.com/strongdm/scimsdk/models"
+)
+
+func TestMatch(t *testing.T) {
+	user := &models.User{
+		ID:       "xxx",
+		Active:   true,
+		UserName: "John.Doe@example.com",
+		Name:     &models.UserName{GivenName: "John", FamilyName: "Doe"},
+		Emails: []models.UserEmail{
+			{Primary: false, Value: "john@personal.com"},
+			{Primary: true, Value: "john.doe@example.com"},
+		},
+		Groups: []models.UserGroupReference{{Value: "yyy", Ref: "https://example.com/Groups/yyy"}},
+		EnterpriseUser: &models.EnterpriseUser{
+			Department: "Engineering",
+			Manager:    &models.EnterpriseUserManager{ID: "zzz"},
+		},
+	}
+
+	t.Run("should match a user against the parsed filters", func(t *testing.T) {
+		matches := map[string]bool{
+			`userName eq "john.doe@example.com"`: true,
+			`userName sw "jane"`:                 false,
+			`urn:ietf:params:scim:schemas:core:2.0:User:name.givenName eq "John"`: true,
+			`name.familyName ew "oe" and active eq true`:                          true,
+			`active eq false or userType pr`:                                      false,
+			`not (userType pr)`:                                                   true,
+			`userType eq null`:                                                    true,
+			`emails co "personal"`:                                                true,
+			`emails[primary eq true and value co "personal"]`:                     false,
+			`emails[primary eq true and value co "example"]`:                      true,
+			`emails[primary eq true].value co "personal"`:                         false,
+			`emails[primary eq false].value co "personal"`:                        true,
+			`groups.$ref sw "https://example.com"`:                                true,
+			`name.middleName pr`:                                                  false,
+			`id gt "xxw" and id lt "xxy"`:                                         true,
+			`urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department eq "engineering"`: true,
+			`urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:manager.value eq "zzz"`:      true,
+			`urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:division pr`:                 false,
+		}
+		for expression, expected := range matches {
+			parsedFilter, err := filter.Parse(expression)
+
+			assert.Nil(t, err, expression)
+			assert.Equal(t, expected, filter.Match(parsedFilter, user), expression)
+		}
+	})
+
+	t.Run("should match a group members by their value", func(t *testing.T) {
+		group := models.Group{
+			DisplayName: "Admins",
+			Members:     []*models.GroupMember{{ID: "xxx", Email: "john@example.com"}, nil},
+		}
+		assertT := assert.New(t)
+
+		assertT.True(filter.Match(filter.Eq("members", "xxx"), group))
+		assertT.True(filter.Match(filter.Values("members", filter.Eq("display", "JOHN@example.com")), group))
+		assertT.False(filter.Match(filter.Eq("members.value", "yyy"), group))
+	})
+
+	t.Run("should compare the numbers of a map of attributes", func(t *testing.T) {
+		resource := map[string]interface{}{"meta": map[string]interface{}{"version": 3.0}}
+		parsedFilter, err := filter.Parse(`meta.version ge 3 and meta.version lt 4`)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.True(filter.Match(parsedFilter, resource))
+		assertT.False(filter.Match(filter.Gt("meta.version", 3), resource))
+	})
+}
@@ -0,0 +1,335 @@
+package filter
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// attributeNamePattern matches the attribute names and sub-attributes of an
+// attribute path, after its optional schema URI.
+var attributeNamePattern = regexp.MustCompile(`^[A-Za-z$][A-Za-z0-9_$-]*$`)
+
+var comparisonOperators = map[string]Operator{
+	"eq": OperatorEq,
+	"ne": OperatorNe,
+	"co": OperatorCo,
+	"sw": OperatorSw,
+	"ew": OperatorEw,
+	"gt": OperatorGt,
+	"ge": OperatorGe,
+	"lt": OperatorLt,
+	"le": OperatorLe,
+}
+
+// SyntaxError is returned when a filter can't be parsed.
+type SyntaxError struct {
+	// Offset is the byte offset of the invalid token in the filter
+	Offset  int
+	Message string
+}
+
+func (err *SyntaxError) Error() string {
+	return fmt.Sprintf("invalid filter at offset %d: %s", err.Offset, err.Message)
+}
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenWord
+	tokenString
+	tokenNumber
+	tokenOpenParen
+	tokenCloseParen
+	tokenOpenBracket
+	tokenCloseBracket
+)
+
+type token struct {
+	kind   tokenKind
+	text   string
+	offset int
+}
+
+func (t token) describe() string {
+	if t.kind == tokenEOF {
+		return "the end of the filter"
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+type parser struct {
+	expression string
+	offset     int
+	current    token
+}
+
+// Parse parses an RFC 7644 filter expression into its AST. The operators and
+// keywords are case-insensitive, and String formats the AST back with the
+// canonical spelling.
+func Parse(expression string) (Filter, error) {
+	p := &parser{expression: expression}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.current.kind == tokenEOF {
+		return nil, &SyntaxError{0, "the filter is empty"}
+	}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.current.kind != tokenEOF {
+		return nil, p.unexpected("a logical operator")
+	}
+	return filter, nil
+}
+
+func (p *parser) parseOr() (Filter, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("or") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{OperatorOr, left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (Filter, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.isKeyword("and") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &Logical{OperatorAnd, left, right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (Filter, error) {
+	if p.isKeyword("not") {
+		if err := p.next(); err != nil {
+			return nil, err
+		}
+		if p.current.kind != tokenOpenParen {
+			return nil, p.unexpected(`"(" after not`)
+		}
+		filter, err := p.parseGroup(tokenCloseParen, ")")
+		if err != nil {
+			return nil, err
+		}
+		return &Negation{filter}, nil
+	}
+	if p.current.kind == tokenOpenParen {
+		return p.parseGroup(tokenCloseParen, ")")
+	}
+	return p.parseAttributeExpression()
+}
+
+// parseGroup parses a filter enclosed by the current token and the close one.
+func (p *parser) parseGroup(close tokenKind, closeText string) (Filter, error) {
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	filter, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.current.kind != close {
+		return nil, p.unexpected(fmt.Sprintf("%q", closeText))
+	}
+	return filter, p.next()
+}
+
+func (p *parser) parseAttributeExpression() (Filter, error) {
+	if p.current.kind != tokenWord {
+		return nil, p.unexpected("an attribute path")
+	}
+	attribute := p.current
+	if err := validateAttributePath(attribute.text); err != nil {
+		return nil, &SyntaxError{attribute.offset, err.Error()}
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	if p.current.kind == tokenOpenBracket {
+		filter, err := p.parseGroup(tokenCloseBracket, "]")
+		if err != nil {
+			return nil, err
+		}
+		valuePath := &ValuePath{attribute.text, filter}
+		if p.current.kind != tokenWord || !strings.HasPrefix(p.current.text, ".") {
+			return valuePath, nil
+		}
+		return p.parseValuePathComparison(valuePath)
+	}
+	return p.parseComparison(attribute.text)
+}
+
+// parseValuePathComparison parses the comparison of the sub-attribute that
+// follows a value path, e.g. emails[type eq "work"].value pr.
+func (p *parser) parseValuePathComparison(valuePath *ValuePath) (Filter, error) {
+	subAttribute := strings.TrimPrefix(p.current.text, ".")
+	if !attributeNamePattern.MatchString(subAttribute) {
+		return nil, &SyntaxError{p.current.offset, fmt.Sprintf("invalid sub-attribute %q", subAttribute)}
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	comparison, err := p.parseComparison(subAttribute)
+	if err != nil {
+		return nil, err
+	}
+	return &ValuePathComparison{valuePath, comparison}, nil
+}
+
+// parseComparison parses the operator and value compared with the attribute.
+func (p *parser) parseComparison(attribute string) (*Comparison, error) {
+	if p.isKeyword("pr") {
+		return &Comparison{attribute, OperatorPr, nil}, p.next()
+	}
+	operator, ok := comparisonOperators[strings.ToLower(p.current.text)]
+	if p.current.kind != tokenWord || !ok {
+		return nil, p.unexpected("a comparison operator")
+	}
+	if err := p.next(); err != nil {
+		return nil, err
+	}
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	return &Comparison{attribute, operator, value}, p.next()
+}
+
+func (p *parser) parseValue() (interface{}, error) {
+	switch p.current.kind {
+	case tokenString:
+		var value string
+		if err := json.Unmarshal([]byte(p.current.text), &value); err != nil {
+			return nil, &SyntaxError{p.current.offset, "invalid string value"}
+		}
+		return value, nil
+	case tokenNumber:
+		var value json.Number
+		if err := json.Unmarshal([]byte(p.current.text), &value); err != nil {
+			return nil, &SyntaxError{p.current.offset, fmt.Sprintf("invalid number %q", p.current.text)}
+		}
+		return value, nil
+	case tokenWord:
+		switch p.current.text {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+	}
+	return nil, p.unexpected("a string, number, boolean or null value")
+}
+
+func (p *parser) isKeyword(keyword string) bool {
+	return p.current.kind == tokenWord && strings.EqualFold(p.current.text, keyword)
+}
+
+func (p *parser) unexpected(expected string) error {
+	return &SyntaxError{p.current.offset, fmt.Sprintf("expected %s, found %s", expected, p.current.describe())}
+}
+
+// next reads the next token of the expression.
+func (p *parser) next() error {
+	p.offset = p.scan(p.offset, func(c byte) bool { return strings.IndexByte(" \t\r\n", c) >= 0 })
+	start := p.offset
+	if start >= len(p.expression) {
+		p.current = token{tokenEOF, "", start}
+		return nil
+	}
+	char := p.expression[start]
+	switch {
+	case strings.IndexByte("()[]", char) >= 0:
+		p.offset++
+		kinds := map[byte]tokenKind{'(': tokenOpenParen, ')': tokenCloseParen, '[': tokenOpenBracket, ']': tokenCloseBracket}
+		p.current = token{kinds[char], string(char), start}
+	case char == '"':
+		end, err := p.scanString(start)
+		if err != nil {
+			return err
+		}
+		p.offset = end
+		p.current = token{tokenString, p.expression[start:end], start}
+	case char == '-' || isDigit(char):
+		p.offset = p.scan(start+1, func(c byte) bool { return isDigit(c) || strings.IndexByte(".eE+-", c) >= 0 })
+		p.current = token{tokenNumber, p.expression[start:p.offset], start}
+	case isWordChar(char):
+		p.offset = p.scan(start+1, isWordChar)
+		p.current = token{tokenWord, p.expression[start:p.offset], start}
+	default:
+		return &SyntaxError{start, fmt.Sprintf("unexpected character %q", char)}
+	}
+	return nil
+}
+
+func (p *parser) scan(offset int, accept func(byte) bool) int {
+	for offset < len(p.expression) && accept(p.expression[offset]) {
+		offset++
+	}
+	return offset
+}
+
+// scanString returns the offset after the closing quote of the string.
+func (p *parser) scanString(start int) (int, error) {
+	for offset := start + 1; offset < len(p.expression); offset++ {
+		switch p.expression[offset] {
+		case '\\':
+			offset++
+		case '"':
+			return offset + 1, nil
+		}
+	}
+	return 0, &SyntaxError{start, "unterminated string value"}
+}
+
+// validateAttributePath validates an attribute path, which is an attribute
+// name with an optional schema URI prefix and sub-attribute.
+func validateAttributePath(path string) error {
+	name := path
+	if index := strings.LastIndexByte(path, ':'); index >= 0 {
+		name = path[index+1:]
+	}
+	segments := strings.Split(name, ".")
+	if len(segments) > 2 {
+		return fmt.Errorf("invalid attribute path %q", path)
+	}
+	for _, segment := range segments {
+		if !attributeNamePattern.MatchString(segment) {
+			return fmt.Errorf("invalid attribute path %q", path)
+		}
+	}
+	return nil
+}
+
+func isDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
+
+func isWordChar(char byte) bool {
+	return char >= 'a' && char <= 'z' || char >= 'A' && char <= 'Z' || isDigit(char) || strings.IndexByte("_$-:.", char) >= 0
+}
@@ -0,0 +1,74 @@
+package filter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	t.Run("should parse a filter and format it back", func(t *testing.T) {
+		expressions := map[string]string{
+			`userName eq "john"`:                                               `userName eq "john"`,
+			`userName Eq "john" AND active EQ true`:                            `userName eq "john" and active eq true`,
+			`title pr or (userType eq "Employee" and not (active eq false))`:   `title pr or userType eq "Employee" and not (active eq false)`,
+			`(title pr or userType eq "Intern") and meta.version gt 1.5e2`:     `(title pr or userType eq "Intern") and meta.version gt 1.5e2`,
+			`emails[type eq "work" and value co "@example.com"]`:               `emails[type eq "work" and value co "@example.com"]`,
+			`urn:ietf:params:scim:schemas:core:2.0:User:name.givenName sw "J"`: `urn:ietf:params:scim:schemas:core:2.0:User:name.givenName sw "J"`,
+			`manager eq null`:                 `manager eq null`,
+			`emails[type eq "work"].value pr`: `emails[type eq "work"].value pr`,
+			`emails[type eq "work"].value CO "@example.com" and active eq true`: `emails[type eq "work"].value co "@example.com" and active eq true`,
+			`displayName eq "a \"quoted\" é"`:                                   `displayName eq "a \"quoted\" é"`,
+		}
+		for expression, expected := range expressions {
+			filter, err := Parse(expression)
+
+			assert.Nil(t, err, expression)
+			assert.Equal(t, expected, filter.String(), expression)
+		}
+	})
+
+	t.Run("should build the same AST as the builder", func(t *testing.T) {
+		filter, err := Parse(`userName sw "j" and (emails[type eq "work"] or not (active eq true))`)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal(And(Sw("userName", "j"), Or(Values("emails", Eq("type", "work")), Not(Eq("active", true)))), filter)
+	})
+
+	t.Run("should parse the comparison of a value path sub-attribute", func(t *testing.T) {
+		filter, err := Parse(`emails[type eq "work"].value co "@example.com"`)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal(&ValuePathComparison{&ValuePath{"emails", Eq("type", "work")}, &Comparison{"value", OperatorCo, "@example.com"}}, filter)
+	})
+
+	t.Run("should return a syntax error with the offset of the invalid token", func(t *testing.T) {
+		errorsByExpression := map[string]*SyntaxError{
+			``:                             {0, "the filter is empty"},
+			`userName`:                     {8, "expected a comparison operator, found the end of the filter"},
+			`userName eq`:                  {11, "expected a string, number, boolean or null value, found the end of the filter"},
+			`userName xx "john"`:           {9, `expected a comparison operator, found "xx"`},
+			`userName eq "john`:            {12, "unterminated string value"},
+			`userName eq john`:             {12, `expected a string, number, boolean or null value, found "john"`},
+			`userName eq "john" active`:    {19, `expected a logical operator, found "active"`},
+			`(userName pr`:                 {12, `expected ")", found the end of the filter`},
+			`emails[type eq "work"`:        {21, `expected "]", found the end of the filter`},
+			`not userName pr`:              {4, `expected "(" after not, found "userName"`},
+			`name.given.name pr`:           {0, `invalid attribute path "name.given.name"`},
+			`userName eq "john" & x`:       {19, `unexpected character '&'`},
+			`meta.version gt 1..2`:         {16, `invalid number "1..2"`},
+			`emails[type eq "work"].value`: {28, "expected a comparison operator, found the end of the filter"},
+			`emails[type eq "work"].1 pr`:  {22, `invalid sub-attribute "1"`},
+		}
+		for expression, expected := range errorsByExpression {
+			_, err := Parse(expression)
+			var syntaxErr *SyntaxError
+
+			assert.True(t, errors.As(err, &syntaxErr), expression)
+			assert.Equal(t, expected, syntaxErr, expression)
+		}
+	})
+}
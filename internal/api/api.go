@@ -6,109 +6,207 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
-	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
 )
 
+// API executes the SCIM requests. The response bodies are decoded into the
+// passed result (when it's not nil) and always drained and closed, so only
+// the response metadata is returned.
 type API interface {
-	Create(ctx context.Context, pathname string, token string, opts *CreateOptions) (*http.Response, error)
-	List(ctx context.Context, pathname string, token string, opts *ListOptions) (*http.Response, error)
-	Find(ctx context.Context, pathname string, token string, opts *FindOptions) (*http.Response, error)
-	Replace(ctx context.Context, pathname string, token string, opts *ReplaceOptions) (*http.Response, error)
-	Update(ctx context.Context, pathname string, token string, opts *UpdateOptions) (*http.Response, error)
-	Delete(ctx context.Context, pathname string, token string, opts *DeleteOptions) (*http.Response, error)
+	Create(ctx context.Context, pathname string, tokenSource TokenSource, opts *CreateOptions, result interface{}) (*Response, error)
+	List(ctx context.Context, pathname string, tokenSource TokenSource, opts *ListOptions, result interface{}) (*Response, error)
+	Find(ctx context.Context, pathname string, tokenSource TokenSource, opts *FindOptions, result interface{}) (*Response, error)
+	Replace(ctx context.Context, pathname string, tokenSource TokenSource, opts *ReplaceOptions, result interface{}) (*Response, error)
+	Update(ctx context.Context, pathname string, tokenSource TokenSource, opts *UpdateOptions, result interface{}) (*Response, error)
+	Delete(ctx context.Context, pathname string, tokenSource TokenSource, opts *DeleteOptions) (*Response, error)
 	ExecuteHTTPRequest(request *http.Request) (*http.Response, error)
 }
 
 type apiImpl struct {
 	internalExecuteHTTPRequest func(*http.Request) (*http.Response, error)
+	retryPolicy                *RetryPolicy
+	middlewares                []Middleware
+	logger                     *requestLogger
+	tracer                     trace.Tracer
+	metrics                    Metrics
+	maxResponseSize            int64
+	rateLimiter                *rateLimiter
+}
+
+// Config defines the settings shared by every request executed by the API.
+type Config struct {
+	// HTTPClient executes the requests (default: NewHTTPClient(nil))
+	HTTPClient *http.Client
+	// RetryPolicy defines how the failed requests are retried (default: DefaultRetryPolicy())
+	RetryPolicy *RetryPolicy
+	// Middlewares wrap every outgoing request, the first one being the outermost
+	Middlewares []Middleware
+	// Logging defines how the requests are logged (default: no logs)
+	Logging *LogConfig
+	// TracerProvider creates the spans of each http attempt (default: no spans)
+	TracerProvider trace.TracerProvider
+	// Metrics records the requests and retries (default: NewNopMetrics())
+	Metrics Metrics
+	// MaxResponseSize is the maximum size in bytes of a decoded response body
+	// (default: 32MiB)
+	MaxResponseSize int64
+	// RateLimit throttles every request sent through the API (default: no limit)
+	RateLimit *RateLimit
 }
 
-func NewAPI() API {
-	return &apiImpl{internalExecuteHTTPRequest}
+// NewAPI creates the API using the passed config, replacing the unset
+// fields by their defaults.
+func NewAPI(config Config) API {
+	if config.HTTPClient == nil {
+		config.HTTPClient = NewHTTPClient(nil)
+	}
+	if config.RetryPolicy == nil {
+		config.RetryPolicy = DefaultRetryPolicy()
+	}
+	if config.Metrics == nil {
+		config.Metrics = NewNopMetrics()
+	}
+	if config.MaxResponseSize <= 0 {
+		config.MaxResponseSize = defaultMaxResponseSize
+	}
+	return &apiImpl{
+		config.HTTPClient.Do,
+		config.RetryPolicy,
+		config.Middlewares,
+		newRequestLogger(config.Logging),
+		NewTracer(config.TracerProvider),
+		config.Metrics,
+		config.MaxResponseSize,
+		newRateLimiter(config.RateLimit),
+	}
 }
 
 const (
 	defaultAPIURL        = "https://app.strongdm.com/provisioning/generic/v2"
 	defaultAPIPageSize   = 5
 	defaultAPIPageOffset = 1
+	// maxListURLLength is the url length after which the list requests are
+	// sent as searches, since many proxies reject longer urls
+	maxListURLLength = 2048
 )
 
-func (api *apiImpl) Create(ctx context.Context, pathname string, token string, opts *CreateOptions) (*http.Response, error) {
+const (
+	searchPathname      = ".search"
+	searchRequestSchema = "urn:ietf:params:scim:api:messages:2.0:SearchRequest"
+)
+
+func (api *apiImpl) Create(ctx context.Context, pathname string, tokenSource TokenSource, opts *CreateOptions, result interface{}) (*Response, error) {
 	url := fmt.Sprint(getBaseURL(opts.BaseAPIURL), "/", pathname)
 	body, err := json.Marshal(opts.Body)
 	if err != nil {
 		return nil, err
 	}
-	reader := ioutil.NopCloser(bytes.NewReader(body))
+	reader := bytes.NewReader(body)
 	request, err := createHTTPRequest(ctx, "POST", url, reader)
 	if err != nil {
 		return nil, err
 	}
-	return ExecuteSafeHTTPRequest(api, request, token)
+	return executeAndDecodeHTTPRequest(api, request, tokenSource, result)
 }
 
-func (api *apiImpl) List(ctx context.Context, pathname string, token string, opts *ListOptions) (*http.Response, error) {
+// List requests a page of resources. The request is sent as a POST to the
+// .search endpoint when the Search option is set or the url is too long, so
+// the filter isn't sent in the url.
+func (api *apiImpl) List(ctx context.Context, pathname string, tokenSource TokenSource, opts *ListOptions, result interface{}) (*Response, error) {
 	url := fmt.Sprint(getBaseURL(opts.BaseAPIURL), "/", pathname)
+	query := prepareRequestQueryParams(opts)
+	if opts.Search || len(url)+len(query)+1 > maxListURLLength {
+		return api.search(ctx, url, tokenSource, opts, result)
+	}
 	request, err := createHTTPRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
-	request.URL.RawQuery = prepareRequestQueryParams(opts)
-	return ExecuteSafeHTTPRequest(api, request, token)
+	request.URL.RawQuery = query
+	return executeAndDecodeHTTPRequest(api, request, tokenSource, result)
 }
 
-func (api *apiImpl) Find(ctx context.Context, pathname string, token string, opts *FindOptions) (*http.Response, error) {
-	url := fmt.Sprint(getBaseURL(opts.BaseAPIURL), "/", pathname, "/", opts.ID)
+func (api *apiImpl) search(ctx context.Context, url string, tokenSource TokenSource, opts *ListOptions, result interface{}) (*Response, error) {
+	body, err := json.Marshal(newSearchRequest(opts))
+	if err != nil {
+		return nil, err
+	}
+	request, err := createHTTPRequest(ctx, "POST", fmt.Sprint(url, "/", searchPathname), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	return executeAndDecodeHTTPRequest(api, request, tokenSource, result)
+}
+
+func (api *apiImpl) Find(ctx context.Context, pathname string, tokenSource TokenSource, opts *FindOptions, result interface{}) (*Response, error) {
+	url := fmt.Sprint(getBaseURL(opts.BaseAPIURL), "/", pathname)
+	if opts.ID != "" {
+		url = fmt.Sprint(url, "/", opts.ID)
+	}
 	request, err := createHTTPRequest(ctx, "GET", url, nil)
 	if err != nil {
 		return nil, err
 	}
-	return ExecuteSafeHTTPRequest(api, request, token)
+	query := request.URL.Query()
+	setAttributesQueryParams(query, opts.Attributes, opts.ExcludedAttributes)
+	request.URL.RawQuery = query.Encode()
+	setHeader(request, "If-None-Match", opts.IfNoneMatch)
+	return executeAndDecodeHTTPRequest(api, request, tokenSource, result)
 }
 
-func (api *apiImpl) Replace(ctx context.Context, pathname string, token string, opts *ReplaceOptions) (*http.Response, error) {
+func (api *apiImpl) Replace(ctx context.Context, pathname string, tokenSource TokenSource, opts *ReplaceOptions, result interface{}) (*Response, error) {
 	url := fmt.Sprint(getBaseURL(opts.BaseAPIURL), "/", pathname, "/", opts.ID)
 	body, err := json.Marshal(opts.Body)
 	if err != nil {
 		return nil, err
 	}
-	reader := ioutil.NopCloser(bytes.NewReader(body))
+	reader := bytes.NewReader(body)
 	request, err := createHTTPRequest(ctx, "PUT", url, reader)
 	if err != nil {
 		return nil, err
 	}
-	return ExecuteSafeHTTPRequest(api, request, token)
+	setHeader(request, "If-Match", opts.IfMatch)
+	return executeAndDecodeHTTPRequest(api, request, tokenSource, result)
 }
 
-func (api *apiImpl) Update(ctx context.Context, pathname string, token string, opts *UpdateOptions) (*http.Response, error) {
+func (api *apiImpl) Update(ctx context.Context, pathname string, tokenSource TokenSource, opts *UpdateOptions, result interface{}) (*Response, error) {
 	url := fmt.Sprint(getBaseURL(opts.BaseAPIURL), "/", pathname, "/", opts.ID)
 	body, err := json.Marshal(opts.Body)
 	if err != nil {
 		return nil, err
 	}
-	reader := ioutil.NopCloser(bytes.NewReader(body))
+	reader := bytes.NewReader(body)
 	request, err := createHTTPRequest(ctx, "PATCH", url, reader)
 	if err != nil {
 		return nil, err
 	}
-	return ExecuteSafeHTTPRequest(api, request, token)
+	setHeader(request, "If-Match", opts.IfMatch)
+	return executeAndDecodeHTTPRequest(api, request, tokenSource, result)
 }
 
-func (api *apiImpl) Delete(ctx context.Context, pathname string, token string, opts *DeleteOptions) (*http.Response, error) {
+func (api *apiImpl) Delete(ctx context.Context, pathname string, tokenSource TokenSource, opts *DeleteOptions) (*Response, error) {
 	url := fmt.Sprint(getBaseURL(opts.BaseAPIURL), "/", pathname, "/", opts.ID)
 	request, err := createHTTPRequest(ctx, "DELETE", url, nil)
 	if err != nil {
 		return nil, err
 	}
-	return ExecuteSafeHTTPRequest(api, request, token)
+	setHeader(request, "If-Match", opts.IfMatch)
+	return executeAndDecodeHTTPRequest(api, request, tokenSource, nil)
 }
 
 func createHTTPRequest(ctx context.Context, method string, url string, body io.Reader) (*http.Request, error) {
-	request, err := http.NewRequest(method, url, body)
-	requestWithCTX := request.WithContext(ctx)
-	return requestWithCTX, err
+	return http.NewRequestWithContext(ctx, method, url, body)
+}
+
+// setHeader sets the optional header of the request, e.g. the preconditions.
+func setHeader(request *http.Request, name, value string) {
+	if value != "" {
+		request.Header.Set(name, value)
+	}
 }
 
 func prepareRequestQueryParams(opts *ListOptions) string {
@@ -118,20 +216,54 @@ func prepareRequestQueryParams(opts *ListOptions) string {
 	if opts.Filter != "" {
 		query.Set("filter", fmt.Sprint(opts.Filter))
 	}
+	if opts.SortBy != "" {
+		query.Set("sortBy", opts.SortBy)
+		if opts.SortOrder != "" {
+			query.Set("sortOrder", opts.SortOrder)
+		}
+	}
+	setAttributesQueryParams(query, opts.Attributes, opts.ExcludedAttributes)
 	return query.Encode()
 }
 
-func getResponseDetails(body io.Reader) string {
-	buff, err := io.ReadAll(body)
-	if err != nil {
-		return err.Error()
+func newSearchRequest(opts *ListOptions) *SearchRequest {
+	searchRequest := &SearchRequest{
+		Schemas:            []string{searchRequestSchema},
+		Attributes:         opts.Attributes,
+		ExcludedAttributes: opts.ExcludedAttributes,
+		Filter:             opts.Filter,
+		StartIndex:         getPageOffset(opts.Offset),
+		Count:              getPageSize(opts.PageSize),
 	}
-	mappedResponse := make(map[string]interface{})
-	err = json.Unmarshal(buff, &mappedResponse)
-	if err != nil {
-		return err.Error()
+	if opts.SortBy != "" {
+		searchRequest.SortBy = opts.SortBy
+		searchRequest.SortOrder = opts.SortOrder
+	}
+	return searchRequest
+}
+
+// isSearchRequest reports whether the request is a search, which only reads
+// the resources even though it's sent as a POST.
+func isSearchRequest(request *http.Request) bool {
+	return request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/"+searchPathname)
+}
+
+// getRequestMethod returns the method used to throttle and retry the
+// request, which is GET for the searches.
+func getRequestMethod(request *http.Request) string {
+	if isSearchRequest(request) {
+		return http.MethodGet
+	}
+	return request.Method
+}
+
+func setAttributesQueryParams(query url.Values, attributes, excludedAttributes []string) {
+	if len(attributes) > 0 {
+		query.Set("attributes", strings.Join(attributes, ","))
+	}
+	if len(excludedAttributes) > 0 {
+		query.Set("excludedAttributes", strings.Join(excludedAttributes, ","))
 	}
-	return fmt.Sprint(mappedResponse["detail"])
 }
 
 func getPageOffset(customOffset int) int {
@@ -0,0 +1,545 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type countingTransport struct {
+	count int
+}
+
+func (transport *countingTransport) RoundTrip(request *http.Request) (*http.Response, error) {
+	transport.count++
+	return http.DefaultTransport.RoundTrip(request)
+}
+
+func TestAPIHTTPClient(t *testing.T) {
+	t.Run("should execute every request using the passed http client", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		transport := &countingTransport{}
+		api := NewAPI(Config{HTTPClient: &http.Client{Transport: transport}})
+		_, err := api.Find(context.Background(), "Users", NewStaticTokenSource("token"), NewFindOptions("xxx", server.URL), nil)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		_, err = api.Delete(context.Background(), "Users", NewStaticTokenSource("token"), NewDeleteOptions("xxx", server.URL))
+		assertT.Nil(err)
+		assertT.Equal(2, transport.count)
+	})
+
+	t.Run("should use the passed transport in the default http client", func(t *testing.T) {
+		transport := &countingTransport{}
+		client := NewHTTPClient(transport)
+		assertT := assert.New(t)
+
+		assertT.Equal(transport, client.Transport)
+		assertT.Equal(defaultHTTPTimeout, client.Timeout)
+	})
+
+	t.Run("should create a default http client when no client is passed", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		api := NewAPI(Config{})
+		_, err := api.Find(context.Background(), "Users", NewStaticTokenSource("token"), NewFindOptions("xxx", server.URL), nil)
+
+		assert.Nil(t, err)
+	})
+}
+
+func TestAPIRetry(t *testing.T) {
+	t.Run("should retry a request until it succeeds when the server returns a transient status", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts < 3 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		api := NewAPI(Config{RetryPolicy: NewRetryPolicy(3, time.Millisecond, time.Millisecond, nil)})
+		_, err := api.Find(context.Background(), "Users", NewStaticTokenSource("token"), NewFindOptions("xxx", server.URL), nil)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal(3, attempts)
+	})
+
+	t.Run("should return an error when the max attempts are exceeded", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusTooManyRequests)
+			w.Write([]byte(`{"detail": "too many requests"}`))
+		}))
+		defer server.Close()
+		api := NewAPI(Config{RetryPolicy: NewRetryPolicy(2, time.Millisecond, time.Millisecond, nil)})
+		_, err := api.Find(context.Background(), "Users", NewStaticTokenSource("token"), NewFindOptions("xxx", server.URL), nil)
+		assertT := assert.New(t)
+
+		assertT.NotNil(err)
+		assertT.Contains(err.Error(), "too many requests")
+		assertT.Equal(2, attempts)
+	})
+
+	t.Run("should not retry a non idempotent request by default", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		api := NewAPI(Config{RetryPolicy: NewRetryPolicy(3, time.Millisecond, time.Millisecond, nil)})
+		_, err := api.Create(context.Background(), "Users", NewStaticTokenSource("token"), NewCreateOptions(map[string]string{}, server.URL), nil)
+		assertT := assert.New(t)
+
+		assertT.NotNil(err)
+		assertT.Equal(1, attempts)
+	})
+
+	t.Run("should retry a non idempotent request with the same body when it's opted in", func(t *testing.T) {
+		bodies := []string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			bodies = append(bodies, string(body))
+			if len(bodies) < 2 {
+				w.Header().Set("Retry-After", "0")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		policy := NewRetryPolicy(3, time.Millisecond, time.Millisecond, []string{http.MethodPost})
+		api := NewAPI(Config{RetryPolicy: policy})
+		_, err := api.Create(context.Background(), "Users", NewStaticTokenSource("token"), NewCreateOptions(map[string]string{"userName": "xxx"}, server.URL), nil)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Len(bodies, 2)
+		assertT.Equal(bodies[0], bodies[1])
+		assertT.Contains(bodies[1], "xxx")
+	})
+
+	t.Run("should limit the Retry-After delay to the max backoff", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts < 2 {
+				w.Header().Set("Retry-After", "86400")
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		api := NewAPI(Config{RetryPolicy: NewRetryPolicy(3, time.Millisecond, time.Millisecond, nil)})
+		_, err := api.Find(ctx, "Users", NewStaticTokenSource("token"), NewFindOptions("xxx", server.URL), nil)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal(2, attempts)
+	})
+
+	t.Run("should stop retrying when the context is done", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}))
+		defer server.Close()
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		api := NewAPI(Config{RetryPolicy: NewRetryPolicy(5, time.Minute, time.Minute, nil)})
+		_, err := api.Find(ctx, "Users", NewStaticTokenSource("token"), NewFindOptions("xxx", server.URL), nil)
+
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	t.Run("should parse the delay in seconds", func(t *testing.T) {
+		delay, ok := parseRetryAfter("2")
+		assertT := assert.New(t)
+
+		assertT.True(ok)
+		assertT.Equal(2*time.Second, delay)
+	})
+
+	t.Run("should parse the delay as an http date", func(t *testing.T) {
+		delay, ok := parseRetryAfter(time.Now().Add(time.Hour).UTC().Format(http.TimeFormat))
+		assertT := assert.New(t)
+
+		assertT.True(ok)
+		assertT.Greater(delay, 59*time.Minute)
+	})
+
+	t.Run("should ignore an invalid value", func(t *testing.T) {
+		_, ok := parseRetryAfter("xxx")
+
+		assert.False(t, ok)
+	})
+}
+
+func TestAPIError(t *testing.T) {
+	t.Run("should return a SCIM error when the server returns an error response", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Request-Id", "req-xxx")
+			w.WriteHeader(http.StatusConflict)
+			w.Write([]byte(`{"schemas": ["urn:ietf:params:scim:api:messages:2.0:Error"], "scimType": "uniqueness", "detail": "user already exists", "status": "409"}`))
+		}))
+		defer server.Close()
+		api := NewAPI(Config{})
+		_, err := api.Create(context.Background(), "Users", NewStaticTokenSource("token"), NewCreateOptions(map[string]string{}, server.URL), nil)
+		assertT := assert.New(t)
+
+		var scimErr *Error
+		assertT.True(errors.As(err, &scimErr))
+		assertT.Equal(http.StatusConflict, scimErr.StatusCode)
+		assertT.Equal("uniqueness", scimErr.ScimType)
+		assertT.Equal("user already exists", scimErr.Detail)
+		assertT.Equal([]string{"urn:ietf:params:scim:api:messages:2.0:Error"}, scimErr.Schemas)
+		assertT.Equal(http.MethodPost, scimErr.Method)
+		assertT.Equal(server.URL+"/Users", scimErr.URL)
+		assertT.Equal("req-xxx", scimErr.RequestID)
+		assertT.ErrorIs(err, ErrConflict)
+		assertT.NotErrorIs(err, ErrNotFound)
+		assertT.Contains(err.Error(), "user already exists")
+	})
+
+	t.Run("should keep the raw body when the error response isn't a JSON", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+			w.Write([]byte("<html>bad gateway</html>"))
+		}))
+		defer server.Close()
+		api := NewAPI(Config{RetryPolicy: NewRetryPolicy(1, 0, 0, nil)})
+		_, err := api.Find(context.Background(), "Users", NewStaticTokenSource("token"), NewFindOptions("xxx", server.URL), nil)
+		assertT := assert.New(t)
+
+		var scimErr *Error
+		assertT.True(errors.As(err, &scimErr))
+		assertT.Equal("<html>bad gateway</html>", string(scimErr.Body))
+		assertT.Empty(scimErr.Detail)
+		assertT.ErrorIs(err, ErrServer)
+		assertT.Contains(err.Error(), "Bad Gateway")
+	})
+
+	t.Run("should redact the filter of the url in the error message", func(t *testing.T) {
+		err := &Error{
+			StatusCode: http.StatusBadRequest,
+			Method:     http.MethodGet,
+			URL:        `https://example.com/Users?count=10&filter=userName+eq+%22alice%40example.com%22`,
+		}
+		assertT := assert.New(t)
+
+		assertT.NotContains(err.Error(), "alice")
+		assertT.Contains(err.Error(), "filter=%5BREDACTED%5D")
+		assertT.Contains(err.Error(), "count=10")
+		assertT.Contains(err.URL, "alice")
+	})
+
+	t.Run("should match the unauthorized and rate limited sentinel errors", func(t *testing.T) {
+		assertT := assert.New(t)
+
+		assertT.ErrorIs(&Error{StatusCode: http.StatusUnauthorized}, ErrUnauthorized)
+		assertT.ErrorIs(&Error{StatusCode: http.StatusTooManyRequests}, ErrRateLimited)
+		assertT.NotErrorIs(&Error{StatusCode: http.StatusBadRequest}, ErrServer)
+	})
+}
+
+func TestAPIMiddlewares(t *testing.T) {
+	t.Run("should execute the middlewares in order with the request operation", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("X-Correlation-Id", r.Header.Get("X-Correlation-Id"))
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		calls := []string{}
+		operations := []Operation{}
+		first := func(operation Operation, request *http.Request, next RequestHandler) (*http.Response, error) {
+			calls = append(calls, "first")
+			request.Header.Set("X-Correlation-Id", "xxx")
+			return next(request)
+		}
+		second := func(operation Operation, request *http.Request, next RequestHandler) (*http.Response, error) {
+			calls = append(calls, "second")
+			operations = append(operations, operation)
+			response, err := next(request)
+			calls = append(calls, response.Header.Get("X-Correlation-Id"))
+			return response, err
+		}
+		api := NewAPI(Config{Middlewares: []Middleware{first, second}})
+		ctx := WithOperation(context.Background(), Operation{Name: "Users.Delete", ResourceID: "yyy"})
+		_, err := api.Delete(ctx, "Users", NewStaticTokenSource("token"), NewDeleteOptions("yyy", server.URL))
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal([]string{"first", "second", "xxx"}, calls)
+		assertT.Equal([]Operation{{Name: "Users.Delete", ResourceID: "yyy"}}, operations)
+	})
+
+	t.Run("should return the middleware error without executing the request", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+		}))
+		defer server.Close()
+		failing := func(operation Operation, request *http.Request, next RequestHandler) (*http.Response, error) {
+			return nil, errors.New("request not allowed")
+		}
+		api := NewAPI(Config{Middlewares: []Middleware{failing}, RetryPolicy: NewRetryPolicy(1, 0, 0, nil)})
+		_, err := api.Find(context.Background(), "Users", NewStaticTokenSource("token"), NewFindOptions("xxx", server.URL), nil)
+		assertT := assert.New(t)
+
+		assertT.EqualError(err, "request not allowed")
+		assertT.Zero(requests)
+	})
+}
+
+type recordingMetrics struct {
+	statusCodes []int
+	retries     int
+}
+
+func (metrics *recordingMetrics) ObserveRequest(operation, method string, statusCode int, latency time.Duration) {
+	metrics.statusCodes = append(metrics.statusCodes, statusCode)
+}
+
+func (metrics *recordingMetrics) IncRetries(operation, method string) {
+	metrics.retries++
+}
+
+func (metrics *recordingMetrics) ObservePage(operation string, items int, err error) {}
+
+func TestAPIMetrics(t *testing.T) {
+	t.Run("should record every attempt and retry", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts == 1 {
+				w.WriteHeader(http.StatusBadGateway)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		metrics := &recordingMetrics{}
+		api := NewAPI(Config{RetryPolicy: NewRetryPolicy(2, time.Millisecond, time.Millisecond, nil), Metrics: metrics})
+		_, err := api.Find(context.Background(), "Users", NewStaticTokenSource("token"), NewFindOptions("xxx", server.URL), nil)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal([]int{http.StatusBadGateway, http.StatusOK}, metrics.statusCodes)
+		assertT.Equal(1, metrics.retries)
+	})
+}
+
+func TestAPIListQuery(t *testing.T) {
+	t.Run("should send the sort params only when the sort attribute is set", func(t *testing.T) {
+		opts := NewListOptions(10, 1, "", "")
+		assertT := assert.New(t)
+
+		assertT.Equal("count=10&startIndex=1", prepareRequestQueryParams(opts))
+		opts.SortBy = "userName"
+		opts.SortOrder = "descending"
+		assertT.Equal("count=10&sortBy=userName&sortOrder=descending&startIndex=1", prepareRequestQueryParams(opts))
+	})
+}
+
+func TestAPIFindSingleton(t *testing.T) {
+	t.Run("should request the resource path when the id is empty", func(t *testing.T) {
+		var path string
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			path = r.URL.Path
+			w.Write([]byte(`{}`))
+		}))
+		defer server.Close()
+		api := NewAPI(Config{})
+		result := map[string]interface{}{}
+		_, err := api.Find(context.Background(), "ServiceProviderConfig", NewStaticTokenSource("token"), NewFindOptions("", server.URL), &result)
+
+		assert.Nil(t, err)
+		assert.Equal(t, "/ServiceProviderConfig", path)
+	})
+}
+
+func TestAPIListSearch(t *testing.T) {
+	newSearchServer := func(method, path, query *string, body *SearchRequest) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*method, *path, *query = r.Method, r.URL.Path, r.URL.RawQuery
+			if r.Method == http.MethodPost {
+				json.NewDecoder(r.Body).Decode(body)
+			}
+			w.Write([]byte(`{"Resources": []}`))
+		}))
+	}
+
+	t.Run("should send the list options in the body of a search when it's requested", func(t *testing.T) {
+		var method, path, query string
+		body := &SearchRequest{}
+		server := newSearchServer(&method, &path, &query, body)
+		defer server.Close()
+		opts := NewListOptions(10, 3, `emails.value eq "user@example.com"`, server.URL)
+		opts.SortBy = "userName"
+		opts.Attributes = []string{"userName"}
+		opts.Search = true
+		_, err := NewAPI(Config{}).List(context.Background(), "Users", NewStaticTokenSource("token"), opts, nil)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal(http.MethodPost, method)
+		assertT.Equal("/Users/.search", path)
+		assertT.Empty(query)
+		assertT.Equal([]string{searchRequestSchema}, body.Schemas)
+		assertT.Equal(`emails.value eq "user@example.com"`, body.Filter)
+		assertT.Equal("userName", body.SortBy)
+		assertT.Equal([]string{"userName"}, body.Attributes)
+		assertT.Equal(3, body.StartIndex)
+		assertT.Equal(10, body.Count)
+	})
+
+	t.Run("should send a search when the filter is too long for the url", func(t *testing.T) {
+		var method, path, query string
+		body := &SearchRequest{}
+		server := newSearchServer(&method, &path, &query, body)
+		defer server.Close()
+		longFilter := strings.Repeat(`id eq "2a5e4a0c-6f7e-4a8e-9c1d-3f3b2b1a0e9d" or `, 50) + `id eq "x"`
+		_, err := NewAPI(Config{}).List(context.Background(), "Groups", NewStaticTokenSource("token"), NewListOptions(0, 0, longFilter, server.URL), nil)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal(http.MethodPost, method)
+		assertT.Equal("/Groups/.search", path)
+		assertT.Equal(longFilter, body.Filter)
+		assertT.Equal(defaultAPIPageSize, body.Count)
+	})
+
+	t.Run("should send the short filters in the url", func(t *testing.T) {
+		var method, path, query string
+		server := newSearchServer(&method, &path, &query, &SearchRequest{})
+		defer server.Close()
+		_, err := NewAPI(Config{}).List(context.Background(), "Users", NewStaticTokenSource("token"), NewListOptions(0, 0, `userName sw "a"`, server.URL), nil)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal(http.MethodGet, method)
+		assertT.Equal("/Users", path)
+		assertT.Contains(query, "filter=")
+	})
+
+	t.Run("should retry the searches as GET requests", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts < 2 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"Resources": []}`))
+		}))
+		defer server.Close()
+		opts := NewListOptions(0, 0, "", server.URL)
+		opts.Search = true
+		api := NewAPI(Config{RetryPolicy: NewRetryPolicy(3, time.Millisecond, time.Millisecond, nil)})
+		_, err := api.List(context.Background(), "Users", NewStaticTokenSource("token"), opts, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, 2, attempts)
+	})
+}
+
+func TestAPIPreconditions(t *testing.T) {
+	newVersionedServer := func(version string, headers *http.Header) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			*headers = r.Header.Clone()
+			if match := r.Header.Get("If-Match"); match != "" && match != version {
+				w.WriteHeader(http.StatusPreconditionFailed)
+				w.Write([]byte(`{"detail": "the resource was changed"}`))
+				return
+			} else if r.Header.Get("If-None-Match") == version {
+				w.Header().Set("ETag", version)
+				w.WriteHeader(http.StatusNotModified)
+				return
+			}
+			w.Header().Set("ETag", version)
+			w.Write([]byte(`{"id": "xxx"}`))
+		}))
+	}
+
+	t.Run("should send the If-Match header and fail with a precondition error when the version changed", func(t *testing.T) {
+		var headers http.Header
+		server := newVersionedServer(`W/"2"`, &headers)
+		defer server.Close()
+		opts := NewReplaceOptions("xxx", map[string]string{}, server.URL)
+		opts.IfMatch = `W/"1"`
+		_, err := NewAPI(Config{}).Replace(context.Background(), "Groups", NewStaticTokenSource("token"), opts, &map[string]interface{}{})
+		assertT := assert.New(t)
+
+		assertT.Equal(`W/"1"`, headers.Get("If-Match"))
+		assertT.True(errors.Is(err, ErrPreconditionFailed))
+		assertT.Contains(err.Error(), "the resource was changed")
+	})
+
+	t.Run("should send the If-Match header in the patch and delete requests", func(t *testing.T) {
+		var headers http.Header
+		server := newVersionedServer(`W/"2"`, &headers)
+		defer server.Close()
+		client := NewAPI(Config{})
+		updateOpts := NewUpdateOptions("xxx", map[string]string{}, server.URL)
+		updateOpts.IfMatch = `W/"2"`
+		_, updateErr := client.Update(context.Background(), "Groups", NewStaticTokenSource("token"), updateOpts, nil)
+		updateHeader := headers.Get("If-Match")
+		deleteOpts := NewDeleteOptions("xxx", server.URL)
+		deleteOpts.IfMatch = `W/"1"`
+		_, deleteErr := client.Delete(context.Background(), "Groups", NewStaticTokenSource("token"), deleteOpts)
+		assertT := assert.New(t)
+
+		assertT.Nil(updateErr)
+		assertT.Equal(`W/"2"`, updateHeader)
+		assertT.True(errors.Is(deleteErr, ErrPreconditionFailed))
+	})
+
+	t.Run("should return a not modified error when the found resource didn't change", func(t *testing.T) {
+		var headers http.Header
+		server := newVersionedServer(`W/"2"`, &headers)
+		defer server.Close()
+		opts := NewFindOptions("xxx", server.URL)
+		opts.IfNoneMatch = `W/"2"`
+		_, err := NewAPI(Config{}).Find(context.Background(), "Groups", NewStaticTokenSource("token"), opts, &map[string]interface{}{})
+		assertT := assert.New(t)
+
+		assertT.True(errors.Is(err, ErrNotModified))
+		responseErr := &Error{}
+		assertT.True(errors.As(err, &responseErr))
+		assertT.Equal(`W/"2"`, responseErr.Header.Get("ETag"))
+	})
+
+	t.Run("should not send the preconditions by default", func(t *testing.T) {
+		var headers http.Header
+		server := newVersionedServer(`W/"2"`, &headers)
+		defer server.Close()
+		response, err := NewAPI(Config{}).Find(context.Background(), "Groups", NewStaticTokenSource("token"), NewFindOptions("xxx", server.URL), &map[string]interface{}{})
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Empty(headers.Values("If-None-Match"))
+		assertT.Equal(`W/"2"`, response.Header.Get("ETag"))
+	})
+}
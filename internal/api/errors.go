@@ -0,0 +1,142 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+var (
+	ErrBadRequest         = errors.New("bad request")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrNotFound           = errors.New("not found")
+	ErrConflict           = errors.New("conflict")
+	ErrRateLimited        = errors.New("rate limited")
+	ErrServer             = errors.New("server error")
+	ErrPreconditionFailed = errors.New("precondition failed")
+	ErrNotModified        = errors.New("not modified")
+)
+
+var requestIDHeaders = []string{"X-Request-Id", "Request-Id", "X-Correlation-Id", "X-Amzn-Requestid"}
+
+// Error represents an error response returned by the SCIM API. It can be
+// compared with the sentinel errors (e.g. ErrNotFound) using errors.Is.
+type Error struct {
+	// StatusCode is the http status code of the response
+	StatusCode int
+	// ScimType is the SCIM detail error keyword (e.g. uniqueness)
+	ScimType string
+	// Detail is the human-readable message sent by the server
+	Detail string
+	// Schemas are the schemas of the error response body
+	Schemas []string
+	// Method is the http method of the failed request
+	Method string
+	// URL is the url of the failed request. The error message redacts the
+	// values of its query that may contain personal data (e.g. the filter)
+	URL string
+	// RequestID is the request identifier sent in the response headers
+	RequestID string
+	// Header is the http header of the response
+	Header http.Header
+	// Body is the raw response body, limited to its first 64KiB
+	Body []byte
+}
+
+type errorResponse struct {
+	Schemas  []string `json:"schemas"`
+	ScimType string   `json:"scimType"`
+	Detail   string   `json:"detail"`
+}
+
+func (err *Error) Error() string {
+	detail := err.Detail
+	if detail == "" {
+		detail = http.StatusText(err.StatusCode)
+	}
+	if err.ScimType != "" {
+		detail = fmt.Sprintf("%s (%s)", detail, err.ScimType)
+	}
+	return fmt.Sprintf("%s %s: %d %s", err.Method, redactURL(err.URL, redactDefaultQuery), err.StatusCode, detail)
+}
+
+func (err *Error) Is(target error) bool {
+	switch target {
+	case ErrBadRequest:
+		return err.StatusCode == http.StatusBadRequest
+	case ErrUnauthorized:
+		return err.StatusCode == http.StatusUnauthorized
+	case ErrForbidden:
+		return err.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return err.StatusCode == http.StatusNotFound
+	case ErrConflict:
+		return err.StatusCode == http.StatusConflict
+	case ErrPreconditionFailed:
+		return err.StatusCode == http.StatusPreconditionFailed
+	case ErrNotModified:
+		return err.StatusCode == http.StatusNotModified
+	case ErrRateLimited:
+		return err.StatusCode == http.StatusTooManyRequests
+	case ErrServer:
+		return err.StatusCode >= http.StatusInternalServerError
+	}
+	return false
+}
+
+// newResponseError creates an Error from a failed response, keeping the raw
+// body when it isn't a SCIM error message.
+func newResponseError(request *http.Request, response *http.Response) *Error {
+	responseErr := &Error{
+		StatusCode: response.StatusCode,
+		Method:     request.Method,
+		URL:        request.URL.String(),
+		Header:     response.Header,
+		RequestID:  getRequestID(response.Header),
+	}
+	if response.Body == nil {
+		return responseErr
+	}
+	defer discardResponseBody(response)
+	body, err := io.ReadAll(io.LimitReader(response.Body, maxErrorBodySize))
+	if err != nil {
+		responseErr.Detail = err.Error()
+		return responseErr
+	}
+	setErrorBody(responseErr, body)
+	return responseErr
+}
+
+// NewOperationError creates an Error from the status and the response body
+// of an operation executed inside another request (e.g. a bulk operation).
+func NewOperationError(method, url string, statusCode int, body []byte) *Error {
+	operationErr := &Error{
+		StatusCode: statusCode,
+		Method:     method,
+		URL:        url,
+	}
+	setErrorBody(operationErr, body)
+	return operationErr
+}
+
+func setErrorBody(responseErr *Error, body []byte) {
+	responseErr.Body = body
+	mappedResponse := errorResponse{}
+	if err := json.Unmarshal(body, &mappedResponse); err == nil {
+		responseErr.Schemas = mappedResponse.Schemas
+		responseErr.ScimType = mappedResponse.ScimType
+		responseErr.Detail = mappedResponse.Detail
+	}
+}
+
+func getRequestID(header http.Header) string {
+	for _, name := range requestIDHeaders {
+		if requestID := header.Get(name); requestID != "" {
+			return requestID
+		}
+	}
+	return ""
+}
@@ -1,30 +1,107 @@
 package api
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
+	"time"
+)
+
+const (
+	defaultHTTPTimeout         = time.Minute
+	defaultMaxIdleConnsPerHost = 10
 )
 
 // ExecuteSafeHTTPRequest controls the executeHTTPRequest response passing an
-// authenticated http request and treating the http response.
-func ExecuteSafeHTTPRequest(api *apiImpl, request *http.Request, token string) (*http.Response, error) {
-	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
-	response, err := api.ExecuteHTTPRequest(request)
+// authenticated http request and treating the http response. Failed requests
+// are retried according to the api retry policy, the token is refreshed once
+// when the server responds with 401 and the error responses are returned as
+// *Error, like the 304 responses to the conditional requests, which have no
+// body to decode.
+func ExecuteSafeHTTPRequest(api *apiImpl, request *http.Request, tokenSource TokenSource) (*http.Response, error) {
+	token, err := tokenSource.Token(request.Context())
 	if err != nil {
 		return nil, err
 	}
-	if response.StatusCode >= 400 {
-		return nil, errors.New(getResponseDetails(response.Body))
+	response, err := executeRetryableHTTPRequest(api, request, token)
+	if err == nil && response.StatusCode == http.StatusUnauthorized {
+		refreshedToken, refreshErr := tokenSource.Refresh(request.Context())
+		if refreshErr != nil {
+			discardResponseBody(response)
+			return nil, refreshErr
+		}
+		if refreshedToken != token {
+			discardResponseBody(response)
+			if request, err = rewindRequest(request); err != nil {
+				return nil, err
+			}
+			response, err = executeRetryableHTTPRequest(api, request, refreshedToken)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+	if response.StatusCode >= 400 || response.StatusCode == http.StatusNotModified {
+		return nil, newResponseError(request, response)
 	}
 	return response, nil
 }
 
+func executeRetryableHTTPRequest(api *apiImpl, request *http.Request, token string) (*http.Response, error) {
+	request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", token))
+	for attempt := 1; ; attempt++ {
+		response, err := executeHTTPAttempt(api, request, attempt)
+		if !api.retryPolicy.shouldRetry(attempt, request, response, err) {
+			return response, err
+		}
+		api.metrics.IncRetries(OperationFromContext(request.Context()).Name, request.Method)
+		delay := api.retryPolicy.backoff(attempt, response)
+		discardResponseBody(response)
+		if err := waitBackoff(request.Context(), delay); err != nil {
+			return nil, err
+		}
+		if request, err = rewindRequest(request); err != nil {
+			return nil, err
+		}
+	}
+}
+
+// executeHTTPAttempt executes a single attempt of the request once the rate
+// limiter allows it, tracing, logging and measuring it.
+func executeHTTPAttempt(api *apiImpl, request *http.Request, attempt int) (*http.Response, error) {
+	if err := api.rateLimiter.wait(request.Context(), getRequestMethod(request)); err != nil {
+		return nil, err
+	}
+	start := time.Now()
+	attemptRequest, span := startAttemptSpan(api.tracer, request, attempt, api.logger.redactQuery)
+	response, err := api.ExecuteHTTPRequest(attemptRequest)
+	latency := time.Since(start)
+	api.rateLimiter.observe(response)
+	endAttemptSpan(span, response, err, api.logger.redactQuery)
+	api.logger.logAttempt(request, response, err, attempt, latency)
+	statusCode := 0
+	if err == nil {
+		statusCode = response.StatusCode
+	}
+	api.metrics.ObserveRequest(OperationFromContext(request.Context()).Name, request.Method, statusCode, latency)
+	return response, err
+}
+
 func (api apiImpl) ExecuteHTTPRequest(request *http.Request) (*http.Response, error) {
-	return api.internalExecuteHTTPRequest(request)
+	operation := OperationFromContext(request.Context())
+	return chainMiddlewares(api.internalExecuteHTTPRequest, operation, api.middlewares)(request)
 }
 
-func internalExecuteHTTPRequest(req *http.Request) (*http.Response, error) {
-	client := http.Client{}
-	return client.Do(req)
+// NewHTTPClient creates the default http client used by the SDK, which keeps
+// the connections alive to reuse them between requests. When a transport is
+// passed it's used instead of the default one.
+func NewHTTPClient(transport http.RoundTripper) *http.Client {
+	if transport == nil {
+		defaultTransport := http.DefaultTransport.(*http.Transport).Clone()
+		defaultTransport.MaxIdleConnsPerHost = defaultMaxIdleConnsPerHost
+		transport = defaultTransport
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   defaultHTTPTimeout,
+	}
 }
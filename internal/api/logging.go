@@ -0,0 +1,268 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	redactedValue     = "[REDACTED]"
+	maxLoggedBodySize = 64 << 10
+)
+
+// DefaultRedactedFields are the JSON attributes and query params redacted in
+// the logs when no custom fields are configured.
+var DefaultRedactedFields = []string{"userName", "name", "displayName", "emails", "phoneNumbers", "addresses", "filter", "password"}
+
+// alwaysRedactedFields are redacted even when custom fields are configured.
+var alwaysRedactedFields = []string{"password"}
+
+// LogConfig defines how the requests are logged.
+type LogConfig struct {
+	Logger *slog.Logger
+	// LogBodies enables the request and response bodies in the debug logs
+	LogBodies bool
+	// RedactedFields are the JSON attributes and query params whose values are
+	// replaced in the logs (default: DefaultRedactedFields). The password is
+	// always redacted
+	RedactedFields []string
+}
+
+type requestLogger struct {
+	logger         *slog.Logger
+	logBodies      bool
+	redactedFields map[string]bool
+}
+
+// defaultRedactedFieldSet redacts the spans and errors when the requests
+// aren't logged.
+var defaultRedactedFieldSet = newRedactedFieldSet(nil)
+
+func newRequestLogger(config *LogConfig) *requestLogger {
+	if config == nil || config.Logger == nil {
+		return nil
+	}
+	return &requestLogger{config.Logger, config.LogBodies, newRedactedFieldSet(config.RedactedFields)}
+}
+
+func newRedactedFieldSet(redactedFields []string) map[string]bool {
+	if redactedFields == nil {
+		redactedFields = DefaultRedactedFields
+	}
+	fields := map[string]bool{}
+	for _, field := range redactedFields {
+		fields[strings.ToLower(field)] = true
+	}
+	for _, field := range alwaysRedactedFields {
+		fields[field] = true
+	}
+	return fields
+}
+
+// logAttempt logs a request attempt, including the bodies at debug level when
+// enabled. The Authorization header is never logged.
+func (logger *requestLogger) logAttempt(request *http.Request, response *http.Response, err error, attempt int, latency time.Duration) {
+	if logger == nil {
+		return
+	}
+	ctx := request.Context()
+	attrs := []slog.Attr{
+		slog.String("operation", OperationFromContext(ctx).Name),
+		slog.String("method", request.Method),
+		slog.String("path", request.URL.Path),
+		slog.String("query", logger.redactQuery(request.URL.Query())),
+		slog.Int("attempt", attempt),
+		slog.Duration("latency", latency),
+	}
+	level := slog.LevelDebug
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", redactErrorURLs(err, logger.redactQuery)))
+	} else {
+		attrs = append(attrs, slog.Int("status", response.StatusCode))
+		if response.StatusCode >= 400 {
+			level = slog.LevelWarn
+		}
+	}
+	if logger.logBodies && logger.logger.Enabled(ctx, slog.LevelDebug) {
+		attrs = append(attrs, slog.String("requestBody", logger.readRequestBody(request)))
+		if response != nil {
+			attrs = append(attrs, slog.String("responseBody", logger.readResponseBody(response)))
+		}
+	}
+	logger.logger.LogAttrs(ctx, level, "scim request", attrs...)
+}
+
+func (logger *requestLogger) readRequestBody(request *http.Request) string {
+	if request.GetBody == nil {
+		return ""
+	}
+	body, err := request.GetBody()
+	if err != nil {
+		return ""
+	}
+	defer body.Close()
+	buff, err := io.ReadAll(body)
+	if err != nil {
+		return ""
+	}
+	return logger.redactBody(buff)
+}
+
+// readResponseBody reads the beginning of the response body and replaces it,
+// so it can still be fully consumed by the caller.
+func (logger *requestLogger) readResponseBody(response *http.Response) string {
+	if response.Body == nil {
+		return ""
+	}
+	body := response.Body
+	buff, err := io.ReadAll(io.LimitReader(body, maxLoggedBodySize))
+	response.Body = struct {
+		io.Reader
+		io.Closer
+	}{io.MultiReader(bytes.NewReader(buff), body), body}
+	if err != nil {
+		return ""
+	}
+	return logger.redactBody(buff)
+}
+
+// redactQuery redacts the configured fields of the query, or the default
+// ones when the requests aren't logged.
+func (logger *requestLogger) redactQuery(query url.Values) string {
+	if logger == nil {
+		return redactDefaultQuery(query)
+	}
+	return redactQueryFields(query, logger.redactedFields)
+}
+
+// redactDefaultQuery redacts the default fields of the query, e.g. in the
+// error messages.
+func redactDefaultQuery(query url.Values) string {
+	return redactQueryFields(query, defaultRedactedFieldSet)
+}
+
+func redactQueryFields(query url.Values, redactedFields map[string]bool) string {
+	for key := range query {
+		if redactedFields[strings.ToLower(key)] {
+			query.Set(key, redactedValue)
+		}
+	}
+	return query.Encode()
+}
+
+func (logger *requestLogger) redactBody(body []byte) string {
+	if len(body) == 0 {
+		return ""
+	}
+	var content interface{}
+	if err := json.Unmarshal(body, &content); err != nil {
+		return fmt.Sprintf("[non-JSON body with %d bytes]", len(body))
+	}
+	redactedBody, err := json.Marshal(logger.redactValue(content))
+	if err != nil {
+		return ""
+	}
+	return string(redactedBody)
+}
+
+// redactValue replaces the values of the redacted attributes, including the
+// values of the PATCH operations whose path targets a redacted attribute.
+func (logger *requestLogger) redactValue(value interface{}) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, item := range typedValue {
+			if logger.redactedFields[strings.ToLower(key)] {
+				typedValue[key] = redactedValue
+			} else {
+				typedValue[key] = logger.redactValue(item)
+			}
+		}
+		if path, ok := typedValue["path"].(string); ok && logger.redactedFields[strings.ToLower(getRootAttribute(path))] {
+			if _, ok := typedValue["value"]; ok {
+				typedValue["value"] = redactedValue
+			}
+		}
+		return typedValue
+	case []interface{}:
+		for i, item := range typedValue {
+			typedValue[i] = logger.redactValue(item)
+		}
+		return typedValue
+	}
+	return value
+}
+
+// redactErrorURLs returns the error message with the query of the request
+// urls redacted, since the transport errors and the response errors include
+// the full url (e.g. with the filter).
+func redactErrorURLs(err error, redactQuery func(url.Values) string) string {
+	message := err.Error()
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		message = strings.ReplaceAll(message, urlErr.URL, redactURL(urlErr.URL, redactQuery))
+	}
+	var responseErr *Error
+	if errors.As(err, &responseErr) {
+		message = strings.ReplaceAll(message, redactURL(responseErr.URL, redactDefaultQuery), redactURL(responseErr.URL, redactQuery))
+	}
+	return message
+}
+
+func redactURL(rawURL string, redactQuery func(url.Values) string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return redactedValue
+	}
+	if parsedURL.RawQuery != "" {
+		parsedURL.RawQuery = redactQuery(parsedURL.Query())
+	}
+	return parsedURL.String()
+}
+
+// RedactError returns the error message with the default fields of the
+// request url queries redacted, e.g. to record it in the spans.
+func RedactError(err error) string {
+	return redactErrorURLs(err, redactDefaultQuery)
+}
+
+// omitQuery redacts the whole query when the redacted fields aren't known.
+func omitQuery(url.Values) string {
+	return redactedValue
+}
+
+func getRootAttribute(path string) string {
+	if index := strings.IndexAny(path, ".["); index >= 0 {
+		return path[:index]
+	}
+	return path
+}
+
+// LogPageFetch logs an iterator page fetch.
+func LogPageFetch(ctx context.Context, logger *slog.Logger, offset, pageSize, items int, haveNextPage bool, err error) {
+	if logger == nil {
+		return
+	}
+	attrs := []slog.Attr{
+		slog.String("operation", OperationFromContext(ctx).Name),
+		slog.Int("offset", offset),
+		slog.Int("pageSize", pageSize),
+		slog.Int("items", items),
+		slog.Bool("haveNextPage", haveNextPage),
+	}
+	level := slog.LevelDebug
+	if err != nil {
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("error", redactErrorURLs(err, omitQuery)))
+	}
+	logger.LogAttrs(ctx, level, "scim page fetched", attrs...)
+}
@@ -0,0 +1,175 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPILogging(t *testing.T) {
+	t.Run("should log each request attempt redacting the token and the PII fields", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if attempts == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.Write([]byte(`{"id": "xxx", "userName": "john@doe.com", "active": true}`))
+		}))
+		defer server.Close()
+		output := &bytes.Buffer{}
+		logger := slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		api := NewAPI(Config{
+			RetryPolicy: NewRetryPolicy(2, time.Millisecond, time.Millisecond, []string{http.MethodPatch}),
+			Logging:     &LogConfig{Logger: logger, LogBodies: true},
+		})
+		body := map[string]interface{}{
+			"Operations": []map[string]interface{}{
+				{"op": "replace", "path": "emails[type eq \"work\"].value", "value": "john@doe.com"},
+				{"op": "replace", "value": map[string]string{"displayName": "John Doe"}},
+			},
+		}
+		ctx := WithOperation(context.Background(), Operation{Name: "Users.Update", ResourceID: "xxx"})
+		_, err := api.Update(ctx, "Users", NewStaticTokenSource("secret-token"), NewUpdateOptions("xxx", body, server.URL), nil)
+		logs := output.String()
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Contains(logs, "level=WARN")
+		assertT.Contains(logs, "status=503")
+		assertT.Contains(logs, "attempt=2")
+		assertT.Contains(logs, "operation=Users.Update")
+		assertT.Contains(logs, "method=PATCH")
+		assertT.Contains(logs, "path=/Users/xxx")
+		assertT.Contains(logs, `\"active\":true`)
+		assertT.NotContains(logs, "secret-token")
+		assertT.NotContains(logs, "john@doe.com")
+		assertT.NotContains(logs, "John Doe")
+	})
+
+	t.Run("should redact the filter query param", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"Resources": []}`))
+		}))
+		defer server.Close()
+		output := &bytes.Buffer{}
+		logger := slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		api := NewAPI(Config{Logging: &LogConfig{Logger: logger}})
+		_, err := api.List(context.Background(), "Users", NewStaticTokenSource("token"), NewListOptions(1, 1, `userName eq "john@doe.com"`, server.URL), nil)
+		logs := output.String()
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Contains(logs, "count=1")
+		assertT.NotContains(logs, "john")
+		assertT.NotContains(logs, "requestBody")
+	})
+
+	t.Run("should only redact the configured fields", func(t *testing.T) {
+		logger := newRequestLogger(&LogConfig{Logger: slog.Default(), RedactedFields: []string{"emails"}})
+		body := logger.redactBody([]byte(`{"userName": "xxx", "emails": [{"value": "yyy"}]}`))
+
+		assert.Equal(t, `{"emails":"[REDACTED]","userName":"xxx"}`, body)
+	})
+}
+
+func TestAPILoggingPassword(t *testing.T) {
+	newLoggedAPI := func(output *bytes.Buffer, redactedFields []string) (API, *httptest.Server) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"id": "xxx"}`))
+		}))
+		logger := slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		return NewAPI(Config{Logging: &LogConfig{Logger: logger, LogBodies: true, RedactedFields: redactedFields}}), server
+	}
+
+	t.Run("should never log the password of the created users", func(t *testing.T) {
+		for _, redactedFields := range [][]string{nil, {"emails"}, {}} {
+			output := &bytes.Buffer{}
+			api, server := newLoggedAPI(output, redactedFields)
+			body := map[string]interface{}{"userName": "xxx", "password": "t1meMa$heen"}
+			_, err := api.Create(context.Background(), "Users", NewStaticTokenSource("token"), NewCreateOptions(body, server.URL), nil)
+			server.Close()
+			assertT := assert.New(t)
+
+			assertT.Nil(err)
+			assertT.Contains(output.String(), "requestBody")
+			assertT.NotContains(output.String(), "t1meMa$heen")
+		}
+	})
+
+	t.Run("should never log the password of the patch and bulk operations", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		api, server := newLoggedAPI(output, []string{"emails"})
+		defer server.Close()
+		patch := map[string]interface{}{
+			"Operations": []map[string]interface{}{
+				{"op": "replace", "path": "password", "value": "t1meMa$heen"},
+				{"op": "replace", "value": map[string]string{"password": "n3wPa$$"}},
+			},
+		}
+		bulk := map[string]interface{}{
+			"Operations": []map[string]interface{}{
+				{"method": "POST", "path": "/Users", "data": map[string]string{"userName": "xxx", "password": "bu1kPa$$"}},
+			},
+		}
+		_, patchErr := api.Update(context.Background(), "Users", NewStaticTokenSource("token"), NewUpdateOptions("xxx", patch, server.URL), nil)
+		_, bulkErr := api.Create(context.Background(), "Bulk", NewStaticTokenSource("token"), NewCreateOptions(bulk, server.URL), nil)
+		logs := output.String()
+		assertT := assert.New(t)
+
+		assertT.Nil(patchErr)
+		assertT.Nil(bulkErr)
+		assertT.NotContains(logs, "t1meMa$heen")
+		assertT.NotContains(logs, "n3wPa$$")
+		assertT.NotContains(logs, "bu1kPa$$")
+	})
+}
+
+type failingTransport struct{}
+
+func (failingTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestAPILoggingErrors(t *testing.T) {
+	t.Run("should redact the filter in the transport errors", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		logger := slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		api := NewAPI(Config{
+			HTTPClient:  &http.Client{Transport: failingTransport{}},
+			RetryPolicy: NewRetryPolicy(1, 0, 0, nil),
+			Logging:     &LogConfig{Logger: logger},
+		})
+		_, err := api.List(context.Background(), "Users", NewStaticTokenSource("token"), NewListOptions(1, 1, `userName eq "jane@example.com"`, "https://example.com/v2"), nil)
+		logs := output.String()
+		assertT := assert.New(t)
+
+		assertT.NotNil(err)
+		assertT.Contains(logs, "level=WARN")
+		assertT.Contains(logs, "connection refused")
+		assertT.Contains(logs, "https://example.com/v2/Users?")
+		assertT.NotContains(logs, "jane")
+	})
+
+	t.Run("should redact the query of the failed page fetches", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		logger := slog.New(slog.NewTextHandler(output, nil))
+		responseErr := &Error{StatusCode: http.StatusBadRequest, Method: http.MethodGet, URL: `https://example.com/v2/Users?filter=userName+eq+%22jane%40example.com%22`}
+		LogPageFetch(context.Background(), logger, 1, 10, 0, false, fmt.Errorf("listing users: %w", responseErr))
+		logs := output.String()
+		assertT := assert.New(t)
+
+		assertT.Contains(logs, "listing users: GET https://example.com/v2/Users?")
+		assertT.Contains(logs, "400 Bad Request")
+		assertT.NotContains(logs, "jane")
+	})
+}
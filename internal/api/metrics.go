@@ -0,0 +1,30 @@
+package api
+
+import (
+	"time"
+)
+
+// Metrics records the SDK operation metrics. The operation is the module
+// method name (e.g. Users.Create) and statusCode is 0 when the request failed
+// without a response.
+type Metrics interface {
+	// ObserveRequest is called after every http attempt
+	ObserveRequest(operation, method string, statusCode int, latency time.Duration)
+	// IncRetries is called before every retried attempt
+	IncRetries(operation, method string)
+	// ObservePage is called after every page fetched by an iterator
+	ObservePage(operation string, items int, err error)
+}
+
+type nopMetrics struct{}
+
+// NewNopMetrics returns a Metrics implementation that records nothing.
+func NewNopMetrics() Metrics {
+	return nopMetrics{}
+}
+
+func (nopMetrics) ObserveRequest(operation, method string, statusCode int, latency time.Duration) {}
+
+func (nopMetrics) IncRetries(operation, method string) {}
+
+func (nopMetrics) ObservePage(operation string, items int, err error) {}
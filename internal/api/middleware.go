@@ -0,0 +1,45 @@
+package api
+
+import (
+	"context"
+	"net/http"
+)
+
+// Operation describes the SDK operation that originated a request.
+type Operation struct {
+	// Name is the module method name, e.g. Users.Create or Groups.UpdateAddMembers
+	Name string
+	// ResourceID is the id of the resource affected by the operation, if any
+	ResourceID string
+}
+
+// RequestHandler executes an http request.
+type RequestHandler func(request *http.Request) (*http.Response, error)
+
+// Middleware wraps the execution of every outgoing request. It must call
+// next to continue the chain and can inspect or change the request and the
+// response.
+type Middleware func(operation Operation, request *http.Request, next RequestHandler) (*http.Response, error)
+
+type operationContextKey struct{}
+
+func WithOperation(ctx context.Context, operation Operation) context.Context {
+	return context.WithValue(ctx, operationContextKey{}, operation)
+}
+
+func OperationFromContext(ctx context.Context) Operation {
+	operation, _ := ctx.Value(operationContextKey{}).(Operation)
+	return operation
+}
+
+// chainMiddlewares wraps the handler with the middlewares, so the first
+// middleware is the outermost one.
+func chainMiddlewares(handler RequestHandler, operation Operation, middlewares []Middleware) RequestHandler {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		middleware, next := middlewares[i], handler
+		handler = func(request *http.Request) (*http.Response, error) {
+			return middleware(operation, request, next)
+		}
+	}
+	return handler
+}
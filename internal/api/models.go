@@ -10,35 +10,79 @@ type CreateOptions struct {
 // - count -> PageSize (default value is 5)
 // - startIndex -> offset (default value is 1)
 // - filter -> filter
+// - sortBy -> SortBy
+// - sortOrder -> SortOrder
+// - attributes -> Attributes
+// - excludedAttributes -> ExcludedAttributes
+// The same options are sent in the body of the searches (POST /.search).
 type ListOptions struct {
 	// PageSize defines the resource count by page
 	PageSize int
 	// Offset defines the page offset referencing to the page - relative to the PageSize
 	Offset int
 	// Filter defines the query filter used in strongDM
-	Filter     string
+	Filter string
+	// SortBy defines the attribute path used to sort the resources
+	SortBy string
+	// SortOrder defines the sort order: ascending or descending
+	SortOrder string
+	// Attributes defines the only attributes returned in the resources
+	Attributes []string
+	// ExcludedAttributes defines the attributes omitted from the resources
+	ExcludedAttributes []string
+	// Search sends the request as a POST to the .search endpoint, so the
+	// filter isn't sent in the url
+	Search     bool
 	BaseAPIURL string
 }
 
+// SearchRequest is the body of the POST requests to the .search endpoints.
+type SearchRequest struct {
+	Schemas            []string `json:"schemas"`
+	Attributes         []string `json:"attributes,omitempty"`
+	ExcludedAttributes []string `json:"excludedAttributes,omitempty"`
+	Filter             string   `json:"filter,omitempty"`
+	SortBy             string   `json:"sortBy,omitempty"`
+	SortOrder          string   `json:"sortOrder,omitempty"`
+	StartIndex         int      `json:"startIndex"`
+	Count              int      `json:"count"`
+}
+
 type FindOptions struct {
-	ID         string
-	BaseAPIURL string
+	// ID is the resource id, omitted for the singleton endpoints (e.g.
+	// ServiceProviderConfig)
+	ID string
+	// Attributes defines the only attributes returned in the resource
+	Attributes []string
+	// ExcludedAttributes defines the attributes omitted from the resource
+	ExcludedAttributes []string
+	// IfNoneMatch is sent in the If-None-Match header, so the server responds
+	// with 304 when the resource still has this version
+	IfNoneMatch string
+	BaseAPIURL  string
 }
 
 type ReplaceOptions struct {
-	ID         string
-	Body       interface{}
+	ID   string
+	Body interface{}
+	// IfMatch is sent in the If-Match header, so the server responds with 412
+	// when the resource doesn't have this version anymore
+	IfMatch    string
 	BaseAPIURL string
 }
 
 type UpdateOptions struct {
-	ID         string
-	Body       interface{}
+	ID   string
+	Body interface{}
+	// IfMatch is sent in the If-Match header
+	IfMatch    string
 	BaseAPIURL string
 }
 
 type DeleteOptions struct {
-	ID         string
+	ID string
+	// IfMatch is sent in the If-Match header
+	IfMatch    string
 	BaseAPIURL string
 }
 
@@ -47,21 +91,21 @@ func NewCreateOptions(body interface{}, baseAPIURL string) *CreateOptions {
 }
 
 func NewListOptions(pageSize, offset int, filter, baseAPIURL string) *ListOptions {
-	return &ListOptions{pageSize, offset, filter, baseAPIURL}
+	return &ListOptions{PageSize: pageSize, Offset: offset, Filter: filter, BaseAPIURL: baseAPIURL}
 }
 
 func NewFindOptions(id, baseAPIURL string) *FindOptions {
-	return &FindOptions{id, baseAPIURL}
+	return &FindOptions{ID: id, BaseAPIURL: baseAPIURL}
 }
 
 func NewReplaceOptions(id string, body interface{}, baseAPIURL string) *ReplaceOptions {
-	return &ReplaceOptions{id, body, baseAPIURL}
+	return &ReplaceOptions{ID: id, Body: body, BaseAPIURL: baseAPIURL}
 }
 
 func NewUpdateOptions(id string, body interface{}, baseAPIURL string) *UpdateOptions {
-	return &UpdateOptions{id, body, baseAPIURL}
+	return &UpdateOptions{ID: id, Body: body, BaseAPIURL: baseAPIURL}
 }
 
 func NewDeleteOptions(id, baseAPIURL string) *DeleteOptions {
-	return &DeleteOptions{id, baseAPIURL}
+	return &DeleteOptions{ID: id, BaseAPIURL: baseAPIURL}
 }
@@ -0,0 +1,218 @@
+package api
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// defaultRateLimitPause is how long the requests are paused after a 429
+// response without a Retry-After header.
+const defaultRateLimitPause = time.Second
+
+// defaultRateLimitMaxPause limits how long the requests are paused by the
+// rate limit headers of the server.
+const defaultRateLimitMaxPause = 30 * time.Second
+
+// rateLimitResetEpoch distinguishes the reset headers sent as unix timestamps
+// from the ones sent as a delay in seconds.
+const rateLimitResetEpoch = 1 << 30
+
+var (
+	rateLimitRemainingHeaders = []string{"RateLimit-Remaining", "X-RateLimit-Remaining"}
+	rateLimitResetHeaders     = []string{"RateLimit-Reset", "X-RateLimit-Reset"}
+)
+
+// RateLimit defines the token buckets used to throttle the requests. A zero
+// rate disables the corresponding bucket.
+type RateLimit struct {
+	// RequestsPerSecond is the rate shared by every request
+	RequestsPerSecond float64
+	// Burst is the number of requests that can be sent at once (default: 1)
+	Burst int
+	// ReadRequestsPerSecond is the rate of the GET requests
+	ReadRequestsPerSecond float64
+	// ReadBurst is the number of GET requests that can be sent at once (default: 1)
+	ReadBurst int
+	// WriteRequestsPerSecond is the rate of the POST, PUT, PATCH and DELETE requests
+	WriteRequestsPerSecond float64
+	// WriteBurst is the number of write requests that can be sent at once (default: 1)
+	WriteBurst int
+	// MaxPause limits the pause requested by the Retry-After and rate limit
+	// reset headers of the server (default: 30s)
+	MaxPause time.Duration
+}
+
+type tokenBucket struct {
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+func newTokenBucket(rate float64, burst int) *tokenBucket {
+	if rate <= 0 {
+		return nil
+	}
+	if burst <= 0 {
+		burst = 1
+	}
+	return &tokenBucket{rate: rate, burst: float64(burst), tokens: float64(burst)}
+}
+
+// reserve takes a token from the bucket and returns how long to wait until
+// it's available.
+func (bucket *tokenBucket) reserve(now time.Time) time.Duration {
+	if !bucket.last.IsZero() {
+		bucket.tokens = math.Min(bucket.burst, bucket.tokens+now.Sub(bucket.last).Seconds()*bucket.rate)
+	}
+	bucket.last = now
+	bucket.tokens--
+	if bucket.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-bucket.tokens / bucket.rate * float64(time.Second))
+}
+
+func (bucket *tokenBucket) cancel() {
+	bucket.tokens = math.Min(bucket.burst, bucket.tokens+1)
+}
+
+// rateLimiter throttles the requests of an API with a global bucket and
+// separate read and write buckets, pausing every request when the server
+// reports that the rate limit was reached.
+type rateLimiter struct {
+	mu          sync.Mutex
+	global      *tokenBucket
+	read        *tokenBucket
+	write       *tokenBucket
+	maxPause    time.Duration
+	pausedUntil time.Time
+}
+
+func newRateLimiter(config *RateLimit) *rateLimiter {
+	if config == nil {
+		return nil
+	}
+	limiter := &rateLimiter{
+		global:   newTokenBucket(config.RequestsPerSecond, config.Burst),
+		read:     newTokenBucket(config.ReadRequestsPerSecond, config.ReadBurst),
+		write:    newTokenBucket(config.WriteRequestsPerSecond, config.WriteBurst),
+		maxPause: config.MaxPause,
+	}
+	if limiter.maxPause <= 0 {
+		limiter.maxPause = defaultRateLimitMaxPause
+	}
+	return limiter
+}
+
+// wait blocks until the request can be sent or the context is done.
+func (limiter *rateLimiter) wait(ctx context.Context, method string) error {
+	if limiter == nil {
+		return nil
+	}
+	limiter.mu.Lock()
+	now := time.Now()
+	buckets := limiter.getBuckets(method)
+	delay := limiter.pausedUntil.Sub(now)
+	for _, bucket := range buckets {
+		if bucketDelay := bucket.reserve(now); bucketDelay > delay {
+			delay = bucketDelay
+		}
+	}
+	limiter.mu.Unlock()
+	if delay <= 0 {
+		return nil
+	}
+	if err := waitBackoff(ctx, delay); err != nil {
+		limiter.mu.Lock()
+		for _, bucket := range buckets {
+			bucket.cancel()
+		}
+		limiter.mu.Unlock()
+		return err
+	}
+	return nil
+}
+
+func (limiter *rateLimiter) getBuckets(method string) []*tokenBucket {
+	buckets := []*tokenBucket{limiter.global, limiter.write}
+	if method == http.MethodGet || method == http.MethodHead {
+		buckets[1] = limiter.read
+	}
+	if buckets[1] == nil {
+		buckets = buckets[:1]
+	}
+	if buckets[0] == nil {
+		buckets = buckets[1:]
+	}
+	return buckets
+}
+
+// observe pauses the requests when the response is a 429 or its rate limit
+// headers report that there are no remaining requests until the reset, up to
+// the max pause.
+func (limiter *rateLimiter) observe(response *http.Response) {
+	if limiter == nil || response == nil {
+		return
+	}
+	delay, ok := getRateLimitDelay(response)
+	if !ok {
+		return
+	}
+	if delay > limiter.maxPause {
+		delay = limiter.maxPause
+	}
+	pausedUntil := time.Now().Add(delay)
+	limiter.mu.Lock()
+	defer limiter.mu.Unlock()
+	if pausedUntil.After(limiter.pausedUntil) {
+		limiter.pausedUntil = pausedUntil
+	}
+}
+
+func getRateLimitDelay(response *http.Response) (time.Duration, bool) {
+	if response.StatusCode == http.StatusTooManyRequests {
+		if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+			return retryAfter, true
+		}
+		if reset, ok := parseRateLimitReset(response.Header); ok {
+			return reset, true
+		}
+		return defaultRateLimitPause, true
+	}
+	if remaining := getFirstHeader(response.Header, rateLimitRemainingHeaders); remaining != "0" {
+		return 0, false
+	}
+	return parseRateLimitReset(response.Header)
+}
+
+// parseRateLimitReset parses the reset header, which can be either the delay
+// in seconds or the unix timestamp of the reset.
+func parseRateLimitReset(header http.Header) (time.Duration, bool) {
+	reset, err := strconv.ParseInt(getFirstHeader(header, rateLimitResetHeaders), 10, 64)
+	if err != nil || reset < 0 {
+		return 0, false
+	}
+	if reset < rateLimitResetEpoch {
+		return time.Duration(reset) * time.Second, true
+	}
+	delay := time.Until(time.Unix(reset, 0))
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, true
+}
+
+func getFirstHeader(header http.Header, names []string) string {
+	for _, name := range names {
+		if value := strings.TrimSpace(header.Get(name)); value != "" {
+			return value
+		}
+	}
+	return ""
+}
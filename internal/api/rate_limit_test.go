@@ -0,0 +1,112 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimiter(t *testing.T) {
+	t.Run("should throttle the requests after the burst", func(t *testing.T) {
+		limiter := newRateLimiter(&RateLimit{RequestsPerSecond: 20, Burst: 2})
+		start := time.Now()
+		for i := 0; i < 4; i++ {
+			assert.Nil(t, limiter.wait(context.Background(), http.MethodGet))
+		}
+
+		assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	})
+
+	t.Run("should use separate budgets for reads and writes", func(t *testing.T) {
+		limiter := newRateLimiter(&RateLimit{ReadRequestsPerSecond: 1000, WriteRequestsPerSecond: 1})
+		assertT := assert.New(t)
+
+		assertT.Nil(limiter.wait(context.Background(), http.MethodPost))
+		start := time.Now()
+		for i := 0; i < 3; i++ {
+			assertT.Nil(limiter.wait(context.Background(), http.MethodGet))
+		}
+		assertT.Less(time.Since(start), 100*time.Millisecond)
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assertT.True(errors.Is(limiter.wait(ctx, http.MethodDelete), context.DeadlineExceeded))
+	})
+
+	t.Run("should return the token when the context is done while waiting", func(t *testing.T) {
+		limiter := newRateLimiter(&RateLimit{RequestsPerSecond: 1})
+		assert.Nil(t, limiter.wait(context.Background(), http.MethodGet))
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		assertT := assert.New(t)
+
+		assertT.True(errors.Is(limiter.wait(ctx, http.MethodGet), context.Canceled))
+		assertT.InDelta(0, limiter.global.tokens, 0.1)
+	})
+
+	t.Run("should pause the requests when the server responds with 429", func(t *testing.T) {
+		limiter := newRateLimiter(&RateLimit{})
+		limiter.observe(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"1"}}})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		assert.True(t, errors.Is(limiter.wait(ctx, http.MethodGet), context.DeadlineExceeded))
+	})
+
+	t.Run("should pause the requests until the rate limit reset", func(t *testing.T) {
+		limiter := newRateLimiter(&RateLimit{MaxPause: 2 * time.Hour})
+		reset := strconv.FormatInt(time.Now().Add(time.Hour).Unix(), 10)
+		limiter.observe(&http.Response{StatusCode: http.StatusOK, Header: http.Header{
+			"X-Ratelimit-Remaining": []string{"0"},
+			"X-Ratelimit-Reset":     []string{reset},
+		}})
+		assertT := assert.New(t)
+
+		assertT.Greater(time.Until(limiter.pausedUntil), 59*time.Minute)
+		limiter.observe(&http.Response{StatusCode: http.StatusOK, Header: http.Header{
+			"Ratelimit-Remaining": []string{"10"},
+			"Ratelimit-Reset":     []string{"1"},
+		}})
+		assertT.Greater(time.Until(limiter.pausedUntil), 59*time.Minute)
+	})
+
+	t.Run("should limit the pause to the max pause", func(t *testing.T) {
+		limiter := newRateLimiter(&RateLimit{})
+		limiter.observe(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"86400"}}})
+		assertT := assert.New(t)
+
+		assertT.LessOrEqual(time.Until(limiter.pausedUntil), defaultRateLimitMaxPause)
+		limiter = newRateLimiter(&RateLimit{MaxPause: 10 * time.Millisecond})
+		limiter.observe(&http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": []string{"86400"}}})
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+		assertT.Nil(limiter.wait(ctx, http.MethodGet))
+	})
+
+	t.Run("should be shared by every request of the api", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusNoContent)
+		}))
+		defer server.Close()
+		api := NewAPI(Config{RateLimit: &RateLimit{RequestsPerSecond: 50, Burst: 1}})
+		start := time.Now()
+		var wg sync.WaitGroup
+		for i := 0; i < 5; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := api.Delete(context.Background(), "Users", NewStaticTokenSource("token"), NewDeleteOptions("xxx", server.URL))
+				assert.Nil(t, err)
+			}()
+		}
+		wg.Wait()
+
+		assert.GreaterOrEqual(t, time.Since(start), 70*time.Millisecond)
+	})
+}
@@ -0,0 +1,89 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+const (
+	defaultMaxResponseSize = 32 << 20
+	// maxErrorBodySize limits the error response bodies kept in *Error
+	maxErrorBodySize = 64 << 10
+	// maxDrainSize limits how much of an unread body is discarded to reuse the
+	// connection. Larger bodies are just closed.
+	maxDrainSize = 256 << 10
+)
+
+// ErrResponseTooLarge is returned when a response body exceeds the maximum
+// response size.
+var ErrResponseTooLarge = errors.New("the response body exceeds the maximum size")
+
+// Response holds the metadata of a response whose body was already decoded.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+}
+
+// executeAndDecodeHTTPRequest executes the request and decodes the response
+// body into the result, always draining and closing the body.
+func executeAndDecodeHTTPRequest(api *apiImpl, request *http.Request, tokenSource TokenSource, result interface{}) (*Response, error) {
+	response, err := ExecuteSafeHTTPRequest(api, request, tokenSource)
+	if err != nil {
+		return nil, err
+	}
+	defer discardResponseBody(response)
+	if result != nil && response.StatusCode != http.StatusNoContent {
+		if err := decodeResponseBody(response.Body, result, api.maxResponseSize); err != nil {
+			return nil, err
+		}
+	}
+	return &Response{response.StatusCode, response.Header}, nil
+}
+
+// decodeResponseBody stream-decodes the JSON body into the result, failing
+// when the body is larger than maxSize.
+func decodeResponseBody(body io.Reader, result interface{}, maxSize int64) error {
+	if body == nil {
+		return errors.New("the response body is empty")
+	}
+	err := json.NewDecoder(&maxSizeReader{body, maxSize}).Decode(result)
+	if err == io.EOF {
+		return errors.New("the response body is empty")
+	} else if errors.Is(err, ErrResponseTooLarge) {
+		return fmt.Errorf("%w of %d bytes", ErrResponseTooLarge, maxSize)
+	}
+	return err
+}
+
+type maxSizeReader struct {
+	reader    io.Reader
+	remaining int64
+}
+
+func (reader *maxSizeReader) Read(buff []byte) (int, error) {
+	if reader.remaining <= 0 {
+		if n, _ := reader.reader.Read(make([]byte, 1)); n > 0 {
+			return 0, ErrResponseTooLarge
+		}
+		return 0, io.EOF
+	}
+	if int64(len(buff)) > reader.remaining {
+		buff = buff[:reader.remaining]
+	}
+	n, err := reader.reader.Read(buff)
+	reader.remaining -= int64(n)
+	return n, err
+}
+
+// discardResponseBody drains a bounded amount of the body, so the connection
+// can be reused, and closes it.
+func discardResponseBody(response *http.Response) {
+	if response == nil || response.Body == nil {
+		return
+	}
+	io.Copy(io.Discard, io.LimitReader(response.Body, maxDrainSize))
+	response.Body.Close()
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type trackedBody struct {
+	io.Reader
+	closed bool
+}
+
+func (body *trackedBody) Close() error {
+	body.closed = true
+	return nil
+}
+
+func newTrackedResponseAPI(statusCode int, content string, maxResponseSize int64) (API, *trackedBody) {
+	body := &trackedBody{Reader: strings.NewReader(content)}
+	api := NewAPI(Config{RetryPolicy: NewRetryPolicy(1, 0, 0, nil), MaxResponseSize: maxResponseSize}).(*apiImpl)
+	api.internalExecuteHTTPRequest = func(request *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: statusCode, Header: http.Header{}, Body: body}, nil
+	}
+	return api, body
+}
+
+func TestAPIResponseBody(t *testing.T) {
+	t.Run("should decode the response body and close it", func(t *testing.T) {
+		api, body := newTrackedResponseAPI(http.StatusOK, `{"Resources": [{"id": "xxx"}, {"id": "yyy"}], "itemsPerPage": 2}`, 0)
+		result := &struct {
+			Resources []struct {
+				ID string `json:"id"`
+			}
+			ItemsPerPage int `json:"itemsPerPage"`
+		}{}
+		response, err := api.List(context.Background(), "Users", NewStaticTokenSource("token"), NewListOptions(2, 1, "", "http://localhost"), result)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal(http.StatusOK, response.StatusCode)
+		assertT.Len(result.Resources, 2)
+		assertT.Equal("yyy", result.Resources[1].ID)
+		assertT.True(body.closed)
+		n, _ := body.Read(make([]byte, 1))
+		assertT.Zero(n)
+	})
+
+	t.Run("should drain and close the body when the result is not decoded", func(t *testing.T) {
+		api, body := newTrackedResponseAPI(http.StatusOK, `{"id": "xxx"}`, 0)
+		_, err := api.Update(context.Background(), "Users", NewStaticTokenSource("token"), NewUpdateOptions("xxx", map[string]string{}, "http://localhost"), nil)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.True(body.closed)
+		n, _ := body.Read(make([]byte, 1))
+		assertT.Zero(n)
+	})
+
+	t.Run("should close the body of an error response", func(t *testing.T) {
+		api, body := newTrackedResponseAPI(http.StatusNotFound, `{"detail": "User not found"}`, 0)
+		_, err := api.Delete(context.Background(), "Users", NewStaticTokenSource("token"), NewDeleteOptions("xxx", "http://localhost"))
+		assertT := assert.New(t)
+
+		assertT.True(errors.Is(err, ErrNotFound))
+		assertT.True(body.closed)
+	})
+
+	t.Run("should fail when the response body exceeds the maximum size", func(t *testing.T) {
+		api, body := newTrackedResponseAPI(http.StatusOK, `{"id": "`+strings.Repeat("x", 64)+`"}`, 32)
+		result := map[string]interface{}{}
+		_, err := api.Find(context.Background(), "Users", NewStaticTokenSource("token"), NewFindOptions("xxx", "http://localhost"), &result)
+		assertT := assert.New(t)
+
+		assertT.True(errors.Is(err, ErrResponseTooLarge))
+		assertT.True(body.closed)
+	})
+
+	t.Run("should fail when the response body is empty", func(t *testing.T) {
+		api, _ := newTrackedResponseAPI(http.StatusOK, "", 0)
+		result := map[string]interface{}{}
+		_, err := api.Find(context.Background(), "Users", NewStaticTokenSource("token"), NewFindOptions("xxx", "http://localhost"), &result)
+
+		assert.EqualError(t, err, "the response body is empty")
+	})
+
+	t.Run("should bound the error response body", func(t *testing.T) {
+		api, _ := newTrackedResponseAPI(http.StatusBadRequest, strings.Repeat("x", 2*maxErrorBodySize), 0)
+		_, err := api.Find(context.Background(), "Users", NewStaticTokenSource("token"), NewFindOptions("xxx", "http://localhost"), nil)
+		var responseErr *Error
+		assertT := assert.New(t)
+
+		assertT.True(errors.As(err, &responseErr))
+		assertT.Len(responseErr.Body, maxErrorBodySize)
+	})
+}
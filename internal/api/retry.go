@@ -0,0 +1,147 @@
+package api
+
+import (
+	"context"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	defaultRetryMaxAttempts    = 3
+	defaultRetryInitialBackoff = 500 * time.Millisecond
+	defaultRetryMaxBackoff     = 30 * time.Second
+)
+
+var defaultRetryableMethods = []string{http.MethodGet, http.MethodPut, http.MethodDelete}
+
+var retryableStatusCodes = map[int]bool{
+	http.StatusTooManyRequests:    true,
+	http.StatusBadGateway:         true,
+	http.StatusServiceUnavailable: true,
+	http.StatusGatewayTimeout:     true,
+}
+
+// RetryPolicy defines how many times and how long to wait before retrying a
+// request that failed because of a transport error or a transient status.
+type RetryPolicy struct {
+	// MaxAttempts is the maximum number of attempts, including the first one
+	MaxAttempts int
+	// InitialBackoff is the base wait time, doubled on each attempt
+	InitialBackoff time.Duration
+	// MaxBackoff limits the wait time calculated with the exponential backoff
+	// or requested by the Retry-After header
+	MaxBackoff time.Duration
+	// RetryableMethods defines the http methods that can be retried
+	RetryableMethods []string
+}
+
+func NewRetryPolicy(maxAttempts int, initialBackoff, maxBackoff time.Duration, retryableMethods []string) *RetryPolicy {
+	policy := &RetryPolicy{maxAttempts, initialBackoff, maxBackoff, retryableMethods}
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = defaultRetryMaxAttempts
+	}
+	if policy.InitialBackoff <= 0 {
+		policy.InitialBackoff = defaultRetryInitialBackoff
+	}
+	if policy.MaxBackoff <= 0 {
+		policy.MaxBackoff = defaultRetryMaxBackoff
+	}
+	if len(policy.RetryableMethods) == 0 {
+		policy.RetryableMethods = defaultRetryableMethods
+	}
+	return policy
+}
+
+func DefaultRetryPolicy() *RetryPolicy {
+	return NewRetryPolicy(0, 0, 0, nil)
+}
+
+func (policy *RetryPolicy) shouldRetry(attempt int, request *http.Request, response *http.Response, err error) bool {
+	if attempt >= policy.MaxAttempts || !policy.isRetryableMethod(getRequestMethod(request)) {
+		return false
+	}
+	if err != nil {
+		return request.Context().Err() == nil
+	}
+	return retryableStatusCodes[response.StatusCode]
+}
+
+func (policy *RetryPolicy) isRetryableMethod(method string) bool {
+	for _, retryableMethod := range policy.RetryableMethods {
+		if strings.EqualFold(retryableMethod, method) {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the wait time before the next attempt, honoring the
+// Retry-After header when the server sends it, up to the MaxBackoff.
+// Otherwise it uses an exponential backoff with full jitter.
+func (policy *RetryPolicy) backoff(attempt int, response *http.Response) time.Duration {
+	if response != nil {
+		if retryAfter, ok := parseRetryAfter(response.Header.Get("Retry-After")); ok {
+			if retryAfter > policy.MaxBackoff {
+				return policy.MaxBackoff
+			}
+			return retryAfter
+		}
+	}
+	backoff := policy.MaxBackoff
+	if shift := attempt - 1; shift < 32 && policy.InitialBackoff<<shift < policy.MaxBackoff {
+		backoff = policy.InitialBackoff << shift
+	}
+	return time.Duration(rand.Int63n(int64(backoff) + 1))
+}
+
+// parseRetryAfter parses the Retry-After header value, which can be either
+// the delay in seconds or an http date.
+func parseRetryAfter(value string) (time.Duration, bool) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return 0, false
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0, false
+		}
+		return time.Duration(seconds) * time.Second, true
+	}
+	date, err := http.ParseTime(value)
+	if err != nil {
+		return 0, false
+	}
+	delay := time.Until(date)
+	if delay < 0 {
+		delay = 0
+	}
+	return delay, true
+}
+
+func waitBackoff(ctx context.Context, delay time.Duration) error {
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rewindRequest returns a copy of the request with a fresh body, so it can
+// be sent again.
+func rewindRequest(request *http.Request) (*http.Request, error) {
+	retryRequest := request.Clone(request.Context())
+	if request.GetBody != nil {
+		body, err := request.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		retryRequest.Body = body
+	}
+	return retryRequest, nil
+}
@@ -5,5 +5,14 @@ import (
 )
 
 func NewMockAPI(internalExecuteHTTPRequest func(*http.Request) (*http.Response, error)) *apiImpl {
-	return &apiImpl{internalExecuteHTTPRequest}
+	return &apiImpl{
+		internalExecuteHTTPRequest,
+		NewRetryPolicy(1, 0, 0, nil),
+		nil,
+		nil,
+		NewTracer(nil),
+		NewNopMetrics(),
+		defaultMaxResponseSize,
+		nil,
+	}
 }
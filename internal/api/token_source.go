@@ -0,0 +1,236 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenExpiryMargin is subtracted from the OAuth2 token lifetime, so the
+// token is renewed before the server rejects it.
+const tokenExpiryMargin = 30 * time.Second
+
+// TokenSource provides the token used to authenticate each request. Token is
+// called before every request and Refresh once when the server responds with
+// 401 Unauthorized, so rotated tokens are picked up without a restart.
+type TokenSource interface {
+	Token(ctx context.Context) (string, error)
+	Refresh(ctx context.Context) (string, error)
+}
+
+type staticTokenSource struct {
+	token string
+}
+
+func NewStaticTokenSource(token string) TokenSource {
+	return &staticTokenSource{strings.TrimSpace(token)}
+}
+
+func (source *staticTokenSource) Token(ctx context.Context) (string, error) {
+	return source.token, nil
+}
+
+func (source *staticTokenSource) Refresh(ctx context.Context) (string, error) {
+	return source.token, nil
+}
+
+type envTokenSource struct {
+	name string
+}
+
+func NewEnvTokenSource(name string) TokenSource {
+	return &envTokenSource{name}
+}
+
+func (source *envTokenSource) Token(ctx context.Context) (string, error) {
+	token := strings.TrimSpace(os.Getenv(source.name))
+	if token == "" {
+		return "", fmt.Errorf("the environment variable %s is empty", source.name)
+	}
+	return token, nil
+}
+
+func (source *envTokenSource) Refresh(ctx context.Context) (string, error) {
+	return source.Token(ctx)
+}
+
+type fileTokenSource struct {
+	path    string
+	mu      sync.Mutex
+	token   string
+	modTime time.Time
+}
+
+// NewFileTokenSource creates a token source that reads the token from a
+// file, reloading it whenever the file is modified.
+func NewFileTokenSource(path string) TokenSource {
+	return &fileTokenSource{path: path}
+}
+
+func (source *fileTokenSource) Token(ctx context.Context) (string, error) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	info, err := os.Stat(source.path)
+	if err != nil {
+		return "", err
+	}
+	if source.token != "" && info.ModTime().Equal(source.modTime) {
+		return source.token, nil
+	}
+	return source.load(info.ModTime())
+}
+
+func (source *fileTokenSource) Refresh(ctx context.Context) (string, error) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	info, err := os.Stat(source.path)
+	if err != nil {
+		return "", err
+	}
+	return source.load(info.ModTime())
+}
+
+func (source *fileTokenSource) load(modTime time.Time) (string, error) {
+	content, err := os.ReadFile(source.path)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("the token file %s is empty", source.path)
+	}
+	source.token = token
+	source.modTime = modTime
+	return token, nil
+}
+
+type commandTokenSource struct {
+	name  string
+	args  []string
+	mu    sync.Mutex
+	token string
+}
+
+// NewCommandTokenSource creates a token source that runs an external command
+// (e.g. a vault CLI) and uses its output as token. The token is cached until
+// the server rejects it.
+func NewCommandTokenSource(name string, args ...string) TokenSource {
+	return &commandTokenSource{name: name, args: args}
+}
+
+func (source *commandTokenSource) Token(ctx context.Context) (string, error) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	if source.token != "" {
+		return source.token, nil
+	}
+	return source.run(ctx)
+}
+
+func (source *commandTokenSource) Refresh(ctx context.Context) (string, error) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	return source.run(ctx)
+}
+
+func (source *commandTokenSource) run(ctx context.Context) (string, error) {
+	output, err := exec.CommandContext(ctx, source.name, source.args...).Output()
+	if err != nil {
+		return "", fmt.Errorf("running the token command %s: %w", source.name, err)
+	}
+	token := strings.TrimSpace(string(output))
+	if token == "" {
+		return "", fmt.Errorf("the token command %s returned an empty token", source.name)
+	}
+	source.token = token
+	return token, nil
+}
+
+// ClientCredentialsConfig defines the OAuth2 client credentials grant used
+// to request the tokens from a token endpoint.
+type ClientCredentialsConfig struct {
+	TokenURL     string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+	// HTTPClient is the http client used to request the tokens
+	HTTPClient *http.Client
+}
+
+type clientCredentialsTokenSource struct {
+	config ClientCredentialsConfig
+	mu     sync.Mutex
+	token  string
+	expiry time.Time
+}
+
+type clientCredentialsResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+// NewClientCredentialsTokenSource creates a token source that requests the
+// token with the OAuth2 client credentials grant, renewing it when expired.
+func NewClientCredentialsTokenSource(config ClientCredentialsConfig) TokenSource {
+	if config.HTTPClient == nil {
+		config.HTTPClient = NewHTTPClient(nil)
+	}
+	return &clientCredentialsTokenSource{config: config}
+}
+
+func (source *clientCredentialsTokenSource) Token(ctx context.Context) (string, error) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	if source.token != "" && (source.expiry.IsZero() || time.Now().Before(source.expiry)) {
+		return source.token, nil
+	}
+	return source.request(ctx)
+}
+
+func (source *clientCredentialsTokenSource) Refresh(ctx context.Context) (string, error) {
+	source.mu.Lock()
+	defer source.mu.Unlock()
+	return source.request(ctx)
+}
+
+func (source *clientCredentialsTokenSource) request(ctx context.Context) (string, error) {
+	form := url.Values{}
+	form.Set("grant_type", "client_credentials")
+	if len(source.config.Scopes) > 0 {
+		form.Set("scope", strings.Join(source.config.Scopes, " "))
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, source.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	request.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	request.SetBasicAuth(url.QueryEscape(source.config.ClientID), url.QueryEscape(source.config.ClientSecret))
+	response, err := source.config.HTTPClient.Do(request)
+	if err != nil {
+		return "", err
+	}
+	if response.StatusCode >= 400 {
+		return "", newResponseError(request, response)
+	}
+	defer discardResponseBody(response)
+	tokenResponse := clientCredentialsResponse{}
+	if err := decodeResponseBody(response.Body, &tokenResponse, maxErrorBodySize); err != nil {
+		return "", err
+	}
+	if tokenResponse.AccessToken == "" {
+		return "", fmt.Errorf("the token endpoint %s returned an empty access token", source.config.TokenURL)
+	}
+	source.token = tokenResponse.AccessToken
+	source.expiry = time.Time{}
+	if tokenResponse.ExpiresIn > 0 {
+		source.expiry = time.Now().Add(time.Duration(tokenResponse.ExpiresIn)*time.Second - tokenExpiryMargin)
+	}
+	return source.token, nil
+}
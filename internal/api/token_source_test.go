@@ -0,0 +1,161 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type rotatingTokenSource struct {
+	tokens  []string
+	current int
+}
+
+func (source *rotatingTokenSource) Token(ctx context.Context) (string, error) {
+	return source.tokens[source.current], nil
+}
+
+func (source *rotatingTokenSource) Refresh(ctx context.Context) (string, error) {
+	if source.current < len(source.tokens)-1 {
+		source.current++
+	}
+	return source.tokens[source.current], nil
+}
+
+func TestTokenSource(t *testing.T) {
+	t.Run("should return the trimmed static token", func(t *testing.T) {
+		token, err := NewStaticTokenSource(" xxx ").Token(context.Background())
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("xxx", token)
+	})
+
+	t.Run("should read the token from an environment variable", func(t *testing.T) {
+		t.Setenv("SCIMSDK_TEST_TOKEN", "xxx")
+		token, err := NewEnvTokenSource("SCIMSDK_TEST_TOKEN").Token(context.Background())
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("xxx", token)
+	})
+
+	t.Run("should return an error when the environment variable is empty", func(t *testing.T) {
+		_, err := NewEnvTokenSource("SCIMSDK_TEST_EMPTY_TOKEN").Token(context.Background())
+
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should reload the token when the file is modified", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "token")
+		os.WriteFile(path, []byte("xxx\n"), 0600)
+		source := NewFileTokenSource(path)
+		token, err := source.Token(context.Background())
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("xxx", token)
+		os.WriteFile(path, []byte("yyy\n"), 0600)
+		os.Chtimes(path, time.Now(), time.Now().Add(time.Minute))
+		token, err = source.Token(context.Background())
+		assertT.Nil(err)
+		assertT.Equal("yyy", token)
+	})
+
+	t.Run("should use the command output as token", func(t *testing.T) {
+		token, err := NewCommandTokenSource("echo", "xxx").Token(context.Background())
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("xxx", token)
+	})
+
+	t.Run("should request and cache the token using the client credentials grant", func(t *testing.T) {
+		requests := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			requests++
+			clientID, clientSecret, _ := r.BasicAuth()
+			r.ParseForm()
+			if clientID != "id" || clientSecret != "secret" || r.Form.Get("grant_type") != "client_credentials" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			fmt.Fprintf(w, `{"access_token": "token-%d", "token_type": "Bearer", "expires_in": 3600}`, requests)
+		}))
+		defer server.Close()
+		source := NewClientCredentialsTokenSource(ClientCredentialsConfig{
+			TokenURL:     server.URL,
+			ClientID:     "id",
+			ClientSecret: "secret",
+		})
+		token, err := source.Token(context.Background())
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("token-1", token)
+		token, _ = source.Token(context.Background())
+		assertT.Equal("token-1", token)
+		token, _ = source.Refresh(context.Background())
+		assertT.Equal("token-2", token)
+	})
+}
+
+func TestAPITokenRefresh(t *testing.T) {
+	t.Run("should refresh the token and retry once when the server responds unauthorized", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			if r.Header.Get("Authorization") != "Bearer new" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		api := NewAPI(Config{})
+		source := &rotatingTokenSource{tokens: []string{"old", "new"}}
+		_, err := api.Find(context.Background(), "Users", source, NewFindOptions("xxx", server.URL), nil)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal(2, attempts)
+	})
+
+	t.Run("should return an unauthorized error when the refreshed token is rejected", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+		api := NewAPI(Config{})
+		source := &rotatingTokenSource{tokens: []string{"old", "new", "newest"}}
+		_, err := api.Find(context.Background(), "Users", source, NewFindOptions("xxx", server.URL), nil)
+		assertT := assert.New(t)
+
+		assertT.ErrorIs(err, ErrUnauthorized)
+		assertT.Equal(2, attempts)
+	})
+
+	t.Run("should not retry when the refreshed token didn't change", func(t *testing.T) {
+		attempts := 0
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			attempts++
+			w.WriteHeader(http.StatusUnauthorized)
+		}))
+		defer server.Close()
+		api := NewAPI(Config{})
+		_, err := api.Find(context.Background(), "Users", NewStaticTokenSource("xxx"), NewFindOptions("xxx", server.URL), nil)
+		assertT := assert.New(t)
+
+		assertT.ErrorIs(err, ErrUnauthorized)
+		assertT.Equal(1, attempts)
+	})
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
+)
+
+// TracerName is the instrumentation name of the spans created by the SDK.
+const TracerName = "github.com/strongdm/scimsdk"
+
+var tracePropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// NewTracer returns the SDK tracer from the provider, or a no-op tracer when
+// the provider is nil.
+func NewTracer(provider trace.TracerProvider) trace.Tracer {
+	if provider == nil {
+		provider = noop.NewTracerProvider()
+	}
+	return provider.Tracer(TracerName)
+}
+
+// startAttemptSpan starts the span of an http attempt and injects the W3C
+// trace context headers in the request. The url query is redacted like in
+// the logs.
+func startAttemptSpan(tracer trace.Tracer, request *http.Request, attempt int, redactQuery func(url.Values) string) (*http.Request, trace.Span) {
+	attrs := []attribute.KeyValue{
+		attribute.String("http.request.method", request.Method),
+		attribute.String("url.full", redactURL(request.URL.String(), redactQuery)),
+	}
+	if attempt > 1 {
+		attrs = append(attrs, attribute.Int("http.request.resend_count", attempt-1))
+	}
+	ctx, span := tracer.Start(request.Context(), "HTTP "+request.Method, trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(attrs...))
+	attemptRequest := request.WithContext(ctx)
+	tracePropagator.Inject(ctx, propagation.HeaderCarrier(attemptRequest.Header))
+	return attemptRequest, span
+}
+
+func endAttemptSpan(span trace.Span, response *http.Response, err error, redactQuery func(url.Values) string) {
+	if err != nil {
+		message := redactErrorURLs(err, redactQuery)
+		span.RecordError(errors.New(message))
+		span.SetStatus(codes.Error, message)
+	} else {
+		span.SetAttributes(attribute.Int("http.response.status_code", response.StatusCode))
+		if response.StatusCode >= 400 {
+			span.SetStatus(codes.Error, http.StatusText(response.StatusCode))
+		}
+	}
+	span.End()
+}
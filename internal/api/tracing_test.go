@@ -0,0 +1,101 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestAPITracing(t *testing.T) {
+	t.Run("should create a span per attempt and propagate the trace context", func(t *testing.T) {
+		traceparents := []string{}
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceparents = append(traceparents, r.Header.Get("traceparent"))
+			if len(traceparents) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+		}))
+		defer server.Close()
+		exporter := tracetest.NewInMemoryExporter()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		api := NewAPI(Config{
+			RetryPolicy:    NewRetryPolicy(2, time.Millisecond, time.Millisecond, nil),
+			TracerProvider: provider,
+		})
+		ctx, parent := provider.Tracer("test").Start(context.Background(), "parent")
+		_, err := api.Find(ctx, "Users", NewStaticTokenSource("token"), NewFindOptions("xxx", server.URL), nil)
+		parent.End()
+		spans := exporter.GetSpans()
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Len(spans, 3)
+		assertT.Equal("HTTP GET", spans[0].Name)
+		assertT.Equal(trace.SpanKindClient, spans[0].SpanKind)
+		assertT.Equal(codes.Error, spans[0].Status.Code)
+		assertT.Contains(spans[0].Attributes, attribute.Int("http.response.status_code", http.StatusServiceUnavailable))
+		assertT.Contains(spans[1].Attributes, attribute.Int("http.request.resend_count", 1))
+		assertT.Contains(spans[1].Attributes, attribute.Int("http.response.status_code", http.StatusOK))
+		assertT.Equal(parent.SpanContext().SpanID(), spans[1].Parent.SpanID())
+		assertT.Len(traceparents, 2)
+		assertT.Contains(traceparents[0], parent.SpanContext().TraceID().String())
+		assertT.Contains(traceparents[1], spans[1].SpanContext.SpanID().String())
+	})
+}
+
+func TestAPITracingRedaction(t *testing.T) {
+	t.Run("should redact the filter of the traced url", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Write([]byte(`{"Resources": []}`))
+		}))
+		defer server.Close()
+		exporter := tracetest.NewInMemoryExporter()
+		api := NewAPI(Config{TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))})
+		_, err := api.List(context.Background(), "Users", NewStaticTokenSource("token"), NewListOptions(1, 1, `userName eq "jane@example.com"`, server.URL), nil)
+		spans := exporter.GetSpans()
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Len(spans, 1)
+		for _, attr := range spans[0].Attributes {
+			if attr.Key == "url.full" {
+				assertT.Contains(attr.Value.AsString(), server.URL+"/Users?")
+				assertT.Contains(attr.Value.AsString(), "count=1")
+				assertT.NotContains(attr.Value.AsString(), "jane")
+			}
+		}
+	})
+
+	t.Run("should redact the filter of the recorded transport errors", func(t *testing.T) {
+		exporter := tracetest.NewInMemoryExporter()
+		api := NewAPI(Config{
+			HTTPClient:     &http.Client{Transport: failingTransport{}},
+			RetryPolicy:    NewRetryPolicy(1, 0, 0, nil),
+			TracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)),
+		})
+		_, err := api.List(context.Background(), "Users", NewStaticTokenSource("token"), NewListOptions(1, 1, `userName eq "jane@example.com"`, "https://example.com/v2"), nil)
+		spans := exporter.GetSpans()
+		assertT := assert.New(t)
+
+		assertT.NotNil(err)
+		assertT.Len(spans, 1)
+		assertT.Contains(spans[0].Status.Description, "connection refused")
+		assertT.NotContains(spans[0].Status.Description, "jane")
+		for _, event := range spans[0].Events {
+			for _, attr := range event.Attributes {
+				assertT.NotContains(attr.Value.Emit(), "jane")
+			}
+		}
+	})
+}
@@ -0,0 +1,146 @@
+// Package attributes resolves the SCIM attribute paths of the porcelain
+// models and the maps of attributes with reflection.
+package attributes
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+var TimeType = reflect.TypeOf(time.Time{})
+
+// Resolve returns the values of the attribute path, flattening the
+// multi-valued attributes. The attributes are resolved ignoring the case from
+// the scim tag or the name of the exported fields. When the path has a schema
+// URI prefix, the attribute is resolved in the field tagged with the schema
+// URI or the entry of the extensions field (tagged with `scim:",extensions"`)
+// and, when there's no such extension, in the resource.
+func Resolve(resource reflect.Value, path string) []reflect.Value {
+	values := flatten(resource, nil)
+	if index := strings.LastIndexByte(path, ':'); index >= 0 {
+		values = resolveSchema(values, path[:index])
+		path = path[index+1:]
+	}
+	for _, name := range strings.Split(path, ".") {
+		var attributeValues []reflect.Value
+		for _, value := range values {
+			attributeValues = flatten(Get(value, name), attributeValues)
+		}
+		values = attributeValues
+	}
+	return values
+}
+
+func resolveSchema(values []reflect.Value, schema string) []reflect.Value {
+	found := false
+	var schemaValues []reflect.Value
+	for _, value := range values {
+		schemaValue := Get(value, schema)
+		if !schemaValue.IsValid() {
+			schemaValue = Get(getExtensions(value), schema)
+		}
+		if schemaValue.IsValid() {
+			found = true
+			schemaValues = flatten(schemaValue, schemaValues)
+		}
+	}
+	if !found {
+		return values
+	}
+	return schemaValues
+}
+
+// getExtensions returns the field of a struct holding its schema extensions.
+func getExtensions(value reflect.Value) reflect.Value {
+	value = Indirect(value)
+	if value.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	for i := 0; i < value.NumField(); i++ {
+		if isExtensionsField(value.Type().Field(i)) {
+			return value.Field(i)
+		}
+	}
+	return reflect.Value{}
+}
+
+func isExtensionsField(field reflect.StructField) bool {
+	options := strings.Split(field.Tag.Get("scim"), ",")
+	return field.IsExported() && len(options) > 1 && options[1] == "extensions"
+}
+
+// Get returns the attribute of a struct or map, or an invalid value when
+// it's not found.
+func Get(value reflect.Value, name string) reflect.Value {
+	value = Indirect(value)
+	switch value.Kind() {
+	case reflect.Struct:
+		for i := 0; i < value.NumField(); i++ {
+			field := value.Type().Field(i)
+			tag := strings.Split(field.Tag.Get("scim"), ",")[0]
+			if field.IsExported() && (strings.EqualFold(tag, name) || tag == "" && strings.EqualFold(field.Name, name)) {
+				return value.Field(i)
+			}
+		}
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return reflect.Value{}
+		}
+		for _, key := range value.MapKeys() {
+			if strings.EqualFold(key.String(), name) {
+				return value.MapIndex(key)
+			}
+		}
+	}
+	return reflect.Value{}
+}
+
+// IsComplex reports whether the value is a complex attribute, whose
+// comparisons use its value sub-attribute.
+func IsComplex(value reflect.Value) bool {
+	kind := value.Kind()
+	return kind == reflect.Struct && value.Type() != TimeType || kind == reflect.Map
+}
+
+// Indirect dereferences the pointers and interfaces, returning an invalid
+// value for the nil ones.
+func Indirect(value reflect.Value) reflect.Value {
+	for value.IsValid() && (value.Kind() == reflect.Pointer || value.Kind() == reflect.Interface) {
+		if value.IsNil() {
+			return reflect.Value{}
+		}
+		value = value.Elem()
+	}
+	return value
+}
+
+// IsPresent reports whether the value is set, treating the empty strings,
+// maps and times as missing.
+func IsPresent(value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.String:
+		return value.String() != ""
+	case reflect.Map:
+		return value.Len() > 0
+	case reflect.Struct:
+		return value.Type() != TimeType || !value.Interface().(time.Time).IsZero()
+	}
+	return value.IsValid()
+}
+
+// flatten appends the value to the values, or its items when it's a slice,
+// skipping the nil pointers.
+func flatten(value reflect.Value, values []reflect.Value) []reflect.Value {
+	value = Indirect(value)
+	if !value.IsValid() {
+		return values
+	}
+	if kind := value.Kind(); kind == reflect.Slice || kind == reflect.Array {
+		for i := 0; i < value.Len(); i++ {
+			values = flatten(value.Index(i), values)
+		}
+		return values
+	}
+	return append(values, value)
+}
@@ -0,0 +1,100 @@
+package attributes
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+
+// Encode converts the porcelain models in the value into maps of attributes,
+// named by their scim or json tag or the lower camel case name of the fields,
+// so the value can be sent in a request body. The unset fields are omitted,
+// except the booleans, and the schema extensions are added as attributes
+// named by their schema URI.
+func Encode(value interface{}) interface{} {
+	return encode(reflect.ValueOf(value))
+}
+
+func encode(value reflect.Value) interface{} {
+	if value.IsValid() && value.Type().Implements(jsonMarshalerType) {
+		return value.Interface()
+	}
+	value = Indirect(value)
+	if !value.IsValid() {
+		return nil
+	}
+	switch value.Kind() {
+	case reflect.Struct:
+		if value.Type() == TimeType {
+			return value.Interface().(time.Time).UTC().Format(time.RFC3339Nano)
+		}
+		return encodeStruct(value)
+	case reflect.Map:
+		if value.Type().Key().Kind() != reflect.String {
+			return value.Interface()
+		}
+		attributes := map[string]interface{}{}
+		for _, key := range value.MapKeys() {
+			attributes[key.String()] = encode(value.MapIndex(key))
+		}
+		return attributes
+	case reflect.Slice, reflect.Array:
+		if value.Kind() == reflect.Slice && value.Type().Elem().Kind() == reflect.Uint8 {
+			return value.Interface()
+		}
+		values := make([]interface{}, 0, value.Len())
+		for i := 0; i < value.Len(); i++ {
+			values = append(values, encode(value.Index(i)))
+		}
+		return values
+	}
+	return value.Interface()
+}
+
+func encodeStruct(value reflect.Value) map[string]interface{} {
+	attributes := map[string]interface{}{}
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name := strings.Split(field.Tag.Get("scim"), ",")[0]
+		if !field.IsExported() || name == "-" {
+			continue
+		}
+		fieldValue := value.Field(i)
+		if fieldValue.Kind() != reflect.Bool && fieldValue.IsZero() {
+			continue
+		}
+		if isExtensionsField(field) && fieldValue.Kind() == reflect.Map {
+			for _, key := range fieldValue.MapKeys() {
+				attributes[key.String()] = encode(fieldValue.MapIndex(key))
+			}
+			continue
+		}
+		if name == "" {
+			name = strings.Split(field.Tag.Get("json"), ",")[0]
+		}
+		if name == "" {
+			name = lowerCamelCase(field.Name)
+		} else if name == "-" {
+			continue
+		}
+		attributes[name] = encode(fieldValue)
+	}
+	return attributes
+}
+
+// lowerCamelCase lowers the leading upper case letters of a field name, e.g.
+// DisplayName is displayName and ID is id.
+func lowerCamelCase(name string) string {
+	runes := []rune(name)
+	for i := 0; i < len(runes) && unicode.IsUpper(runes[i]); i++ {
+		if i > 0 && i+1 < len(runes) && unicode.IsLower(runes[i+1]) {
+			break
+		}
+		runes[i] = unicode.ToLower(runes[i])
+	}
+	return string(runes)
+}
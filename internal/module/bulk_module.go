@@ -0,0 +1,269 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/strongdm/scimsdk/internal/api"
+	"github.com/strongdm/scimsdk/internal/service"
+	"github.com/strongdm/scimsdk/models"
+	"go.opentelemetry.io/otel/attribute"
+)
+
+const defaultBulkRequestSchema = "urn:ietf:params:scim:api:messages:2.0:BulkRequest"
+
+type bulkModuleImpl struct {
+	service               service.BulkService
+	providedURL           string
+	instrumentation       Instrumentation
+	serviceProviderConfig *ServiceProviderConfigLoader
+}
+
+func NewBulkModule(service service.BulkService, providedURL string, instrumentation Instrumentation, serviceProviderConfig *ServiceProviderConfigLoader) *bulkModuleImpl {
+	return &bulkModuleImpl{service, providedURL, instrumentation, serviceProviderConfig}
+}
+
+// bulkExecution holds the results of the operations already executed and the
+// ids of the resources they created.
+type bulkExecution struct {
+	failOnErrors int
+	errors       int
+	ids          map[string]string
+	response     *models.BulkResponse
+}
+
+func (execution *bulkExecution) addResult(operation *bulkOperation, response *service.BulkOperationResponse) {
+	result := convertBulkOperationResponseToPorcelain(operation, response)
+	if result.Error != nil {
+		execution.errors++
+	} else if operation.method == http.MethodPost && operation.bulkID != "" {
+		execution.ids[operation.bulkID] = result.ID
+	}
+	execution.response.Operations = append(execution.response.Operations, result)
+}
+
+func (execution *bulkExecution) isStopped() bool {
+	return execution.failOnErrors > 0 && execution.errors >= execution.failOnErrors
+}
+
+// Execute sends the operations to the Bulk endpoint, splitting them in
+// several requests according to the server maxOperations and maxPayloadSize.
+// When the server doesn't support bulk operations, they're executed one by
+// one with the same results. The operations missing in the bulk responses are
+// reported as failed with the 424 status. When a request fails after some
+// operations were executed, their results are returned along with the error.
+func (module *bulkModuleImpl) Execute(ctx context.Context, request *models.BulkRequest) (_ *models.BulkResponse, err error) {
+	if request == nil {
+		request = &models.BulkRequest{}
+	}
+	ctx, span := module.instrumentation.startOperation(ctx, bulkOperationPrefix, "Execute", "", attribute.Int("scim.bulk.operations", len(request.Operations)))
+	defer endSpan(span, &err)
+	if request.FailOnErrors < 0 {
+		return nil, errors.New("the bulk fail on errors must be positive")
+	}
+	operations, err := convertPorcelainToBulkOperations(request.Operations)
+	if err != nil {
+		return nil, err
+	}
+	execution := &bulkExecution{
+		failOnErrors: request.FailOnErrors,
+		ids:          map[string]string{},
+		response:     &models.BulkResponse{Operations: []*models.BulkOperationResult{}},
+	}
+	bulkSupport, err := module.serviceProviderConfig.getBulkSupport(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if bulkSupport == nil {
+		err = module.executeSequentially(ctx, operations, execution)
+	} else {
+		err = module.executeInBatches(ctx, operations, execution, bulkSupport)
+	}
+	return execution.response, err
+}
+
+func (module *bulkModuleImpl) executeInBatches(ctx context.Context, operations []*bulkOperation, execution *bulkExecution, bulkSupport *service.BulkFeatureResponse) error {
+	for len(operations) > 0 && !execution.isStopped() {
+		request := &service.BulkRequest{Schemas: []string{defaultBulkRequestSchema}}
+		if execution.failOnErrors > 0 {
+			request.FailOnErrors = execution.failOnErrors - execution.errors
+		}
+		batch, err := getBulkBatch(request, operations, execution.ids, bulkSupport)
+		if err != nil {
+			return err
+		}
+		response, err := module.service.Execute(ctx, newServiceCreateOptions(request, module.providedURL))
+		if err != nil {
+			return err
+		}
+		for index, operationResponse := range matchBulkOperationResponses(batch, response.Operations) {
+			if operationResponse == nil {
+				operationResponse = newMissingBulkOperationResponse(batch[index])
+			}
+			execution.addResult(batch[index], operationResponse)
+		}
+		operations = operations[len(batch):]
+	}
+	return nil
+}
+
+// matchBulkOperationResponses returns the response of each operation of the
+// batch, since the servers don't have to keep the order of the operations.
+// The POST responses are matched by their bulkId and the others by their
+// method and location, falling back to their index when they don't have them.
+func matchBulkOperationResponses(batch []*bulkOperation, responses []*service.BulkOperationResponse) []*service.BulkOperationResponse {
+	matches := make([]*service.BulkOperationResponse, len(batch))
+	unmatched := []int{}
+	for index, response := range responses {
+		if response == nil {
+			continue
+		}
+		if operationIndex := findBulkOperationIndex(batch, matches, response); operationIndex >= 0 {
+			matches[operationIndex] = response
+		} else if response.BulkID == "" {
+			unmatched = append(unmatched, index)
+		}
+	}
+	for _, index := range unmatched {
+		response := responses[index]
+		if index < len(batch) && matches[index] == nil && (response.Method == "" || strings.EqualFold(response.Method, batch[index].method)) {
+			matches[index] = response
+		}
+	}
+	return matches
+}
+
+func findBulkOperationIndex(batch []*bulkOperation, matches []*service.BulkOperationResponse, response *service.BulkOperationResponse) int {
+	for index, operation := range batch {
+		if matches[index] != nil || (response.Method != "" && !strings.EqualFold(response.Method, operation.method)) {
+			continue
+		}
+		if response.BulkID != "" {
+			if response.BulkID == operation.bulkID {
+				return index
+			}
+		} else if operation.method != http.MethodPost && isBulkOperationLocation(operation, response.Location) {
+			return index
+		}
+	}
+	return -1
+}
+
+func isBulkOperationLocation(operation *bulkOperation, location string) bool {
+	if location == "" {
+		return false
+	}
+	locationURL, err := url.Parse(location)
+	if err != nil {
+		return false
+	}
+	return strings.HasSuffix(strings.TrimSuffix(locationURL.Path, "/"), operation.getPath())
+}
+
+// getBulkBatch adds to the request the first operations that fit in the
+// server limits, replacing their references to the resources created by the
+// previous batches. The first operation is always added.
+func getBulkBatch(request *service.BulkRequest, operations []*bulkOperation, ids map[string]string, bulkSupport *service.BulkFeatureResponse) ([]*bulkOperation, error) {
+	encodedRequest, err := json.Marshal(request)
+	if err != nil {
+		return nil, err
+	}
+	size := len(encodedRequest)
+	for index, operation := range operations {
+		if bulkSupport.MaxOperations > 0 && index >= bulkSupport.MaxOperations {
+			return operations[:index], nil
+		}
+		operation.resolveBulkIDs(ids)
+		operationRequest := convertBulkOperationToRequest(operation)
+		encodedOperation, err := json.Marshal(operationRequest)
+		if err != nil {
+			return nil, err
+		}
+		size += len(encodedOperation) + 1
+		if index > 0 && bulkSupport.MaxPayloadSize > 0 && size > bulkSupport.MaxPayloadSize {
+			return operations[:index], nil
+		}
+		request.Operations = append(request.Operations, operationRequest)
+	}
+	return operations, nil
+}
+
+func (module *bulkModuleImpl) executeSequentially(ctx context.Context, operations []*bulkOperation, execution *bulkExecution) error {
+	for _, operation := range operations {
+		if execution.isStopped() {
+			return nil
+		}
+		operation.resolveBulkIDs(execution.ids)
+		if operation.hasBulkIDRef() {
+			execution.addResult(operation, newUnresolvedBulkIDResponse(operation))
+			continue
+		}
+		response, err := module.service.ExecuteOperation(ctx, &service.BulkOperationOptions{
+			Method:     operation.method,
+			BulkID:     operation.bulkID,
+			Pathname:   operation.pathname,
+			ID:         operation.id,
+			Body:       operation.data,
+			BaseAPIURL: module.providedURL,
+		})
+		var responseErr *api.Error
+		if errors.As(err, &responseErr) {
+			response = &service.BulkOperationResponse{Status: service.BulkStatusResponse(responseErr.StatusCode), Response: responseErr.Body}
+		} else if err != nil {
+			return err
+		}
+		execution.addResult(operation, response)
+	}
+	return nil
+}
+
+// newUnresolvedBulkIDResponse creates the conflict response the servers send
+// when an operation references a resource that wasn't created.
+func newUnresolvedBulkIDResponse(operation *bulkOperation) *service.BulkOperationResponse {
+	return newBulkOperationErrorResponse(operation, http.StatusConflict, "the operation references a bulk id of a resource that wasn't created")
+}
+
+// newMissingBulkOperationResponse creates the failed response of an operation
+// the server didn't return, e.g. because it stopped after the fail on errors,
+// so it isn't mistaken for a successful one.
+func newMissingBulkOperationResponse(operation *bulkOperation) *service.BulkOperationResponse {
+	return newBulkOperationErrorResponse(operation, http.StatusFailedDependency, "the server didn't return the result of the operation")
+}
+
+func newBulkOperationErrorResponse(operation *bulkOperation, status int, detail string) *service.BulkOperationResponse {
+	body, _ := json.Marshal(map[string]string{
+		"status": fmt.Sprint(status),
+		"detail": detail,
+	})
+	return &service.BulkOperationResponse{
+		Method:   operation.method,
+		BulkID:   operation.bulkID,
+		Status:   service.BulkStatusResponse(status),
+		Response: body,
+	}
+}
+
+// getBulkSupport returns the bulk limits of the server, or nil when the bulk
+// operations must be executed one by one because the server doesn't support
+// them or doesn't have a ServiceProviderConfig. The other errors loading the
+// ServiceProviderConfig are returned.
+func (loader *ServiceProviderConfigLoader) getBulkSupport(ctx context.Context) (*service.BulkFeatureResponse, error) {
+	if loader == nil {
+		return &service.BulkFeatureResponse{Supported: true}, nil
+	}
+	config, err := loader.Load(ctx)
+	if isUnsupportedEndpoint(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	if config.Bulk == nil || !config.Bulk.Supported {
+		return nil, nil
+	}
+	return config.Bulk, nil
+}
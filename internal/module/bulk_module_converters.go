@@ -0,0 +1,291 @@
+package module
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	"github.com/strongdm/scimsdk/internal/api"
+	"github.com/strongdm/scimsdk/internal/service"
+	"github.com/strongdm/scimsdk/models"
+)
+
+// bulkOperation is a validated bulk operation with its encoded body, so the
+// bulkId references can be replaced before sending it.
+type bulkOperation struct {
+	method   string
+	bulkID   string
+	pathname string
+	id       string
+	data     json.RawMessage
+}
+
+func (operation *bulkOperation) getPath() string {
+	if operation.id == "" {
+		return "/" + operation.pathname
+	}
+	return fmt.Sprint("/", operation.pathname, "/", operation.id)
+}
+
+// bulkIDRefAttributes are the attributes whose values can reference the
+// resources created by the other operations.
+var bulkIDRefAttributes = map[string]bool{"members": true, "manager": true}
+
+// resolveBulkIDs replaces the references to the resources already created
+// with their ids.
+func (operation *bulkOperation) resolveBulkIDs(ids map[string]string) {
+	if strings.HasPrefix(operation.id, models.BulkIDRef("")) {
+		if id, ok := ids[strings.TrimPrefix(operation.id, models.BulkIDRef(""))]; ok {
+			operation.id = id
+		}
+	}
+	data, err := decodeBulkOperationData(operation.data)
+	if err != nil {
+		return
+	}
+	resolved := false
+	data = walkBulkIDRefs(data, func(ref string) string {
+		if id, ok := ids[strings.TrimPrefix(ref, models.BulkIDRef(""))]; ok {
+			resolved = true
+			return id
+		}
+		return ref
+	})
+	if !resolved {
+		return
+	}
+	if encodedData, err := json.Marshal(data); err == nil {
+		operation.data = encodedData
+	}
+}
+
+func (operation *bulkOperation) hasBulkIDRef() bool {
+	if strings.HasPrefix(operation.id, models.BulkIDRef("")) {
+		return true
+	}
+	data, err := decodeBulkOperationData(operation.data)
+	if err != nil {
+		return false
+	}
+	found := false
+	walkBulkIDRefs(data, func(ref string) string {
+		found = true
+		return ref
+	})
+	return found
+}
+
+func decodeBulkOperationData(data json.RawMessage) (interface{}, error) {
+	var decoded interface{}
+	if len(data) == 0 {
+		return decoded, nil
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&decoded)
+	return decoded, err
+}
+
+// walkBulkIDRefs calls the function with the bulk id references of the
+// members and manager attributes, including the values of the PATCH
+// operations targeting them, and replaces them with its result. The other
+// attributes are left untouched, even when their values look like references.
+func walkBulkIDRefs(value interface{}, replaceFn func(string) string) interface{} {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		path, isPatchOperation := typedValue["path"].(string)
+		_, hasOp := typedValue["op"]
+		isPatchOperation = isPatchOperation && hasOp
+		for key, item := range typedValue {
+			if bulkIDRefAttributes[strings.ToLower(key)] || (isPatchOperation && key == "value" && bulkIDRefAttributes[getPatchPathAttribute(path)]) {
+				typedValue[key] = replaceBulkIDRefs(item, replaceFn)
+			} else {
+				typedValue[key] = walkBulkIDRefs(item, replaceFn)
+			}
+		}
+	case []interface{}:
+		for index, item := range typedValue {
+			typedValue[index] = walkBulkIDRefs(item, replaceFn)
+		}
+	}
+	return value
+}
+
+// replaceBulkIDRefs replaces the references of a members or manager value,
+// which can be a single reference or complex values with a value or $ref.
+func replaceBulkIDRefs(value interface{}, replaceFn func(string) string) interface{} {
+	switch typedValue := value.(type) {
+	case string:
+		if strings.HasPrefix(typedValue, models.BulkIDRef("")) {
+			return replaceFn(typedValue)
+		}
+	case map[string]interface{}:
+		for _, key := range []string{"value", "$ref"} {
+			if ref, ok := typedValue[key].(string); ok && strings.HasPrefix(ref, models.BulkIDRef("")) {
+				typedValue[key] = replaceFn(ref)
+			}
+		}
+	case []interface{}:
+		for index, item := range typedValue {
+			typedValue[index] = replaceBulkIDRefs(item, replaceFn)
+		}
+	}
+	return value
+}
+
+// getPatchPathAttribute returns the lowercase attribute targeted by a PATCH
+// path, without its schema, filter and sub-attribute.
+func getPatchPathAttribute(path string) string {
+	if index := strings.Index(path, "["); index >= 0 {
+		path = path[:index]
+	}
+	if index := strings.LastIndex(path, ":"); index >= 0 {
+		path = path[index+1:]
+	}
+	if index := strings.Index(path, "."); index >= 0 {
+		path = path[:index]
+	}
+	return strings.ToLower(path)
+}
+
+func convertPorcelainToBulkOperations(operations []*models.BulkOperation) ([]*bulkOperation, error) {
+	if len(operations) == 0 {
+		return nil, errors.New("you must pass the bulk operations")
+	}
+	bulkIDs := map[string]bool{}
+	bulkOperations := []*bulkOperation{}
+	for _, operation := range operations {
+		bulkOperation, err := convertPorcelainToBulkOperation(operation)
+		if err != nil {
+			return nil, err
+		}
+		if bulkOperation.bulkID != "" {
+			if bulkIDs[bulkOperation.bulkID] {
+				return nil, fmt.Errorf("the bulk id %q is duplicated", bulkOperation.bulkID)
+			}
+			bulkIDs[bulkOperation.bulkID] = true
+		}
+		bulkOperations = append(bulkOperations, bulkOperation)
+	}
+	return bulkOperations, nil
+}
+
+func convertPorcelainToBulkOperation(operation *models.BulkOperation) (*bulkOperation, error) {
+	if operation == nil {
+		return nil, errors.New("you must pass the bulk operation")
+	} else if operation.ResourceType != models.BulkResourceUsers && operation.ResourceType != models.BulkResourceGroups {
+		return nil, errors.New("the bulk operation resource type must be Users or Groups")
+	} else if operation.Method == models.BulkMethodPost && operation.BulkID == "" {
+		return nil, errors.New("you must pass the BulkID of the POST bulk operations")
+	} else if operation.Method != models.BulkMethodPost && operation.ID == "" {
+		return nil, errors.New("you must pass the resource id")
+	}
+	body, resourceType, method, err := convertPorcelainToBulkOperationBody(operation)
+	if err != nil {
+		return nil, err
+	} else if method != operation.Method || resourceType != "" && resourceType != operation.ResourceType {
+		return nil, fmt.Errorf("invalid data %T for the %s %s bulk operation", operation.Data, operation.Method, operation.ResourceType)
+	}
+	bulkOperation := &bulkOperation{
+		method:   string(operation.Method),
+		bulkID:   operation.BulkID,
+		pathname: string(operation.ResourceType),
+	}
+	if operation.Method != models.BulkMethodPost {
+		bulkOperation.id = operation.ID
+	}
+	if body != nil {
+		if bulkOperation.data, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+	return bulkOperation, nil
+}
+
+// convertPorcelainToBulkOperationBody converts the operation data into its
+// request body, returning the resource type and method the data belongs to.
+func convertPorcelainToBulkOperationBody(operation *models.BulkOperation) (interface{}, models.BulkResourceType, models.BulkMethod, error) {
+	var body interface{}
+	var err error
+	switch data := operation.Data.(type) {
+	case nil:
+		return nil, "", models.BulkMethodDelete, nil
+	case models.CreateUser:
+		body, err = convertPorcelainToCreateUserRequest(&data)
+		return body, models.BulkResourceUsers, models.BulkMethodPost, err
+	case models.ReplaceUser:
+		body, err = convertPorcelainToReplaceUserRequest(operation.ID, &data)
+		return body, models.BulkResourceUsers, models.BulkMethodPut, err
+	case models.UpdateUser:
+		return convertPorcelainToUpdateUserRequest(data), models.BulkResourceUsers, models.BulkMethodPatch, nil
+	case *models.UserPatch:
+		body, err = convertPorcelainToPatchUserRequest(data)
+		return body, models.BulkResourceUsers, models.BulkMethodPatch, err
+	case models.CreateGroupBody:
+		body, err = convertPorcelainToCreateGroupRequest(&data)
+		return body, models.BulkResourceGroups, models.BulkMethodPost, err
+	case models.ReplaceGroupBody:
+		body, err = convertPorcelainToReplaceGroupRequest(&data)
+		return body, models.BulkResourceGroups, models.BulkMethodPut, err
+	case *models.GroupPatch:
+		body, err = convertPorcelainToPatchGroupRequest(data)
+		return body, models.BulkResourceGroups, models.BulkMethodPatch, err
+	case models.UpdateGroupReplaceName:
+		body, err = convertPorcelainToUpdateGroupNameRequest(data)
+		return body, models.BulkResourceGroups, models.BulkMethodPatch, err
+	case models.UpdateGroupAddMembers:
+		body, err = convertPorcelainToUpdateGroupAddMembersRequest(data.Members)
+		return body, models.BulkResourceGroups, models.BulkMethodPatch, err
+	case models.UpdateGroupReplaceMembers:
+		body, err = convertPorcelainToUpdateGroupReplaceMembersRequest(data.Members)
+		return body, models.BulkResourceGroups, models.BulkMethodPatch, err
+	case models.UpdateGroupRemoveMembers:
+		body, err = convertPorcelainToUpdateGroupRemoveMembersRequest(data.Filter)
+		return body, models.BulkResourceGroups, models.BulkMethodPatch, err
+	}
+	return nil, "", "", fmt.Errorf("invalid bulk operation data %T", operation.Data)
+}
+
+func convertBulkOperationToRequest(operation *bulkOperation) *service.BulkOperationRequest {
+	return &service.BulkOperationRequest{
+		Method: operation.method,
+		BulkID: operation.bulkID,
+		Path:   operation.getPath(),
+		Data:   operation.data,
+	}
+}
+
+func convertBulkOperationResponseToPorcelain(operation *bulkOperation, response *service.BulkOperationResponse) *models.BulkOperationResult {
+	result := &models.BulkOperationResult{
+		Method:   models.BulkMethod(operation.method),
+		BulkID:   operation.bulkID,
+		ID:       operation.id,
+		Location: response.Location,
+		Version:  response.Version,
+		Status:   int(response.Status),
+	}
+	if result.Status >= 400 {
+		result.Error = api.NewOperationError(operation.method, operation.getPath(), result.Status, response.Response)
+	} else if id := getBulkResourceID(response); id != "" {
+		result.ID = id
+	}
+	return result
+}
+
+// getBulkResourceID returns the id of the resource returned by the operation,
+// or the last segment of its location.
+func getBulkResourceID(response *service.BulkOperationResponse) string {
+	resource := service.BulkResourceResponse{}
+	if err := json.Unmarshal(response.Response, &resource); err == nil && resource.ID != "" {
+		return resource.ID
+	}
+	location, err := url.Parse(response.Location)
+	if err != nil || location.Path == "" {
+		return ""
+	}
+	return path.Base(location.Path)
+}
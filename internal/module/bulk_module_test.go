@@ -0,0 +1,430 @@
+package module
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strongdm/scimsdk/internal/api"
+	"github.com/strongdm/scimsdk/internal/service"
+	"github.com/strongdm/scimsdk/models"
+)
+
+func TestBulkModuleExecute(t *testing.T) {
+	t.Run("should send the operations in a bulk request", func(t *testing.T) {
+		var requests []*service.BulkRequest
+		mockApi := getMockedAPI(mockedApiExecuteWithBulk(`{"bulk": {"supported": true}}`, &requests))
+		module := newMockBulkModule(mockApi)
+		response, err := module.Execute(context.Background(), getMockBulkRequest(0))
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Len(requests, 1)
+		assertT.Len(requests[0].Operations, 3)
+		assertT.Equal("/Groups", requests[0].Operations[1].Path)
+		assertT.Contains(string(requests[0].Operations[1].Data), `"value":"bulkId:user"`)
+		assertT.Equal("/Groups/bulkId:group", requests[0].Operations[2].Path)
+		assertT.Len(response.Operations, 3)
+		assertT.Equal("user-id", response.Operations[0].ID)
+		assertT.Equal(http.StatusCreated, response.Operations[0].Status)
+		assertT.Equal("group-id", response.Operations[1].ID)
+		assertT.Nil(response.Operations[1].Error)
+	})
+
+	t.Run("should split the operations by the server max operations", func(t *testing.T) {
+		var requests []*service.BulkRequest
+		mockApi := getMockedAPI(mockedApiExecuteWithBulk(`{"bulk": {"supported": true, "maxOperations": 2}}`, &requests))
+		module := newMockBulkModule(mockApi)
+		response, err := module.Execute(context.Background(), getMockBulkRequest(0))
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Len(requests, 2)
+		assertT.Len(requests[0].Operations, 2)
+		assertT.Len(requests[1].Operations, 1)
+		assertT.Equal("/Groups/group-id", requests[1].Operations[0].Path)
+		assertT.Len(response.Operations, 3)
+	})
+
+	t.Run("should split the operations by the server max payload size", func(t *testing.T) {
+		var requests []*service.BulkRequest
+		mockApi := getMockedAPI(mockedApiExecuteWithBulk(`{"bulk": {"supported": true, "maxPayloadSize": 300}}`, &requests))
+		module := newMockBulkModule(mockApi)
+		response, err := module.Execute(context.Background(), getMockBulkRequest(0))
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Greater(len(requests), 1)
+		for _, request := range requests {
+			assertT.Len(request.Operations, 1)
+		}
+		assertT.Len(response.Operations, 3)
+	})
+
+	t.Run("should execute the operations one by one when the server doesn't support bulk", func(t *testing.T) {
+		var requests []*service.BulkRequest
+		var paths []string
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			paths = append(paths, request.Method+" "+strings.TrimPrefix(request.URL.Path, "/provisioning/generic/v2"))
+			return mockedApiExecuteWithBulk(`{"bulk": {"supported": false}}`, &requests)(request)
+		})
+		module := newMockBulkModule(mockApi)
+		response, err := module.Execute(context.Background(), getMockBulkRequest(0))
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Empty(requests)
+		assertT.Equal([]string{"GET /ServiceProviderConfig", "POST /Users", "POST /Groups", "PATCH /Groups/group-id"}, paths)
+		assertT.Len(response.Operations, 3)
+		assertT.Equal("user-id", response.Operations[0].ID)
+		assertT.Equal(http.StatusCreated, response.Operations[0].Status)
+		assertT.Equal("group-id", response.Operations[1].ID)
+		assertT.Equal(http.StatusNoContent, response.Operations[2].Status)
+	})
+
+	t.Run("should stop executing the operations one by one after the fail on errors", func(t *testing.T) {
+		var paths []string
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			if strings.HasSuffix(request.URL.Path, "/ServiceProviderConfig") {
+				return newMockBulkResponse(http.StatusOK, `{}`), nil
+			}
+			paths = append(paths, request.Method+" "+strings.TrimPrefix(request.URL.Path, "/provisioning/generic/v2"))
+			return newMockBulkResponse(http.StatusConflict, `{"scimType": "uniqueness", "detail": "the user already exists"}`), nil
+		})
+		module := newMockBulkModule(mockApi)
+		response, err := module.Execute(context.Background(), getMockBulkRequest(1))
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal([]string{"POST /Users"}, paths)
+		assertT.Len(response.Operations, 1)
+		assertT.Equal(http.StatusConflict, response.Operations[0].Status)
+		assertT.True(errors.Is(response.Operations[0].Error, api.ErrConflict))
+	})
+
+	t.Run("should fail the operations referencing a resource that wasn't created", func(t *testing.T) {
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			if strings.HasSuffix(request.URL.Path, "/ServiceProviderConfig") {
+				return newMockBulkResponse(http.StatusOK, `{}`), nil
+			}
+			return newMockBulkResponse(http.StatusBadRequest, `{"detail": "invalid user"}`), nil
+		})
+		module := newMockBulkModule(mockApi)
+		response, err := module.Execute(context.Background(), getMockBulkRequest(0))
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Len(response.Operations, 3)
+		assertT.Equal(http.StatusBadRequest, response.Operations[0].Status)
+		assertT.Equal(http.StatusConflict, response.Operations[1].Status)
+		assertT.Equal(http.StatusConflict, response.Operations[2].Status)
+	})
+
+	t.Run("should match the results to the operations when the server changes their order", func(t *testing.T) {
+		var requests []*service.BulkRequest
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			response, err := mockedApiExecuteWithBulk(`{"bulk": {"supported": true, "maxOperations": 2}}`, &requests)(request)
+			if !strings.HasSuffix(request.URL.Path, "/Bulk") {
+				return response, err
+			}
+			bulkResponse := &service.BulkResponse{}
+			json.NewDecoder(response.Body).Decode(bulkResponse)
+			for left, right := 0, len(bulkResponse.Operations)-1; left < right; left, right = left+1, right-1 {
+				bulkResponse.Operations[left], bulkResponse.Operations[right] = bulkResponse.Operations[right], bulkResponse.Operations[left]
+			}
+			body, _ := json.Marshal(bulkResponse)
+			return newMockBulkResponse(http.StatusOK, string(body)), nil
+		})
+		module := newMockBulkModule(mockApi)
+		response, err := module.Execute(context.Background(), getMockBulkRequest(0))
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Len(requests, 2)
+		assertT.Equal("/Groups/group-id", requests[1].Operations[0].Path)
+		assertT.Len(response.Operations, 3)
+		assertT.Equal("user-id", response.Operations[0].ID)
+		assertT.Equal("user", response.Operations[0].BulkID)
+		assertT.Equal("group-id", response.Operations[1].ID)
+		assertT.Equal("group", response.Operations[1].BulkID)
+	})
+
+	t.Run("should report the operations missing in the bulk response as failed", func(t *testing.T) {
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			if strings.HasSuffix(request.URL.Path, "/ServiceProviderConfig") {
+				return newMockBulkResponse(http.StatusOK, `{"bulk": {"supported": true}}`), nil
+			}
+			return newMockBulkResponse(http.StatusOK, `{"Operations": [{"method": "POST", "bulkId": "user", "status": "400", "response": {"detail": "invalid user"}}]}`), nil
+		})
+		module := newMockBulkModule(mockApi)
+		response, err := module.Execute(context.Background(), getMockBulkRequest(1))
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Len(response.Operations, 3)
+		assertT.Equal(http.StatusBadRequest, response.Operations[0].Status)
+		assertT.Equal("group", response.Operations[1].BulkID)
+		assertT.Equal(http.StatusFailedDependency, response.Operations[1].Status)
+		assertT.Error(response.Operations[1].Error)
+		assertT.Equal(http.StatusFailedDependency, response.Operations[2].Status)
+		assertT.Error(response.Operations[2].Error)
+	})
+
+	t.Run("should return the results of the previous batches along with the error of a failed batch", func(t *testing.T) {
+		var requests []*service.BulkRequest
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			if len(requests) > 0 && strings.HasSuffix(request.URL.Path, "/Bulk") {
+				return newMockBulkResponse(http.StatusInternalServerError, `{"detail": "internal error"}`), nil
+			}
+			return mockedApiExecuteWithBulk(`{"bulk": {"supported": true, "maxOperations": 2}}`, &requests)(request)
+		})
+		module := newMockBulkModule(mockApi)
+		response, err := module.Execute(context.Background(), getMockBulkRequest(0))
+		assertT := assert.New(t)
+
+		assertT.Error(err)
+		assertT.Len(response.Operations, 2)
+		assertT.Equal("user-id", response.Operations[0].ID)
+		assertT.Equal("group-id", response.Operations[1].ID)
+	})
+
+	t.Run("should return the results of the previous operations along with the error of a failed operation", func(t *testing.T) {
+		var requests []*service.BulkRequest
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			if request.Method == http.MethodPost && strings.HasSuffix(request.URL.Path, "/Groups") {
+				return nil, errors.New("connection refused")
+			}
+			return mockedApiExecuteWithBulk(`{"bulk": {"supported": false}}`, &requests)(request)
+		})
+		module := newMockBulkModule(mockApi)
+		response, err := module.Execute(context.Background(), getMockBulkRequest(0))
+		assertT := assert.New(t)
+
+		assertT.ErrorContains(err, "connection refused")
+		assertT.Len(response.Operations, 1)
+		assertT.Equal("user-id", response.Operations[0].ID)
+	})
+
+	t.Run("should execute the operations one by one when the server doesn't have a service provider config", func(t *testing.T) {
+		var paths []string
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			paths = append(paths, request.Method+" "+strings.TrimPrefix(request.URL.Path, "/provisioning/generic/v2"))
+			if strings.HasSuffix(request.URL.Path, "/ServiceProviderConfig") {
+				return newMockBulkResponse(http.StatusNotFound, `{"detail": "not found"}`), nil
+			}
+			return mockedApiExecuteWithBulk(`{}`, &[]*service.BulkRequest{})(request)
+		})
+		module := newMockBulkModule(mockApi)
+		response, err := module.Execute(context.Background(), getMockBulkRequest(0))
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal([]string{"GET /ServiceProviderConfig", "POST /Users", "POST /Groups", "PATCH /Groups/group-id"}, paths)
+		assertT.Len(response.Operations, 3)
+	})
+
+	t.Run("should return an error when the service provider config can't be loaded", func(t *testing.T) {
+		var paths []string
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			paths = append(paths, request.Method+" "+strings.TrimPrefix(request.URL.Path, "/provisioning/generic/v2"))
+			return newMockBulkResponse(http.StatusServiceUnavailable, `{"detail": "unavailable"}`), nil
+		})
+		module := newMockBulkModule(mockApi)
+		response, err := module.Execute(context.Background(), getMockBulkRequest(0))
+		assertT := assert.New(t)
+
+		assertT.Nil(response)
+		assertT.ErrorIs(err, api.ErrServer)
+		assertT.Equal([]string{"GET /ServiceProviderConfig"}, paths)
+	})
+
+	t.Run("should return an error when the operation data doesn't match its method", func(t *testing.T) {
+		module := newMockBulkModule(getMockedAPI(nil))
+		response, err := module.Execute(context.Background(), &models.BulkRequest{
+			Operations: []*models.BulkOperation{{
+				Method:       models.BulkMethodPatch,
+				ResourceType: models.BulkResourceUsers,
+				ID:           "user-id",
+				Data:         models.CreateUser{UserName: "user@example.com", GivenName: "User", FamilyName: "Name"},
+			}},
+		})
+		assertT := assert.New(t)
+
+		assertT.Nil(response)
+		assertT.EqualError(err, "invalid data models.CreateUser for the PATCH Users bulk operation")
+	})
+
+	t.Run("should return an error when the create operation doesn't have a bulk id", func(t *testing.T) {
+		module := newMockBulkModule(getMockedAPI(nil))
+		response, err := module.Execute(context.Background(), &models.BulkRequest{
+			Operations: []*models.BulkOperation{{
+				Method:       models.BulkMethodPost,
+				ResourceType: models.BulkResourceGroups,
+				Data:         models.CreateGroupBody{DisplayName: "Group"},
+			}},
+		})
+
+		assert.Nil(t, response)
+		assert.Error(t, err)
+	})
+}
+
+func newMockBulkModule(mockApi api.API) *bulkModuleImpl {
+	return NewBulkModule(service.NewBulkService(mockApi, api.NewStaticTokenSource("token")), "", Instrumentation{}, newMockServiceProviderConfigLoader(mockApi))
+}
+
+func getMockBulkRequest(failOnErrors int) *models.BulkRequest {
+	return &models.BulkRequest{
+		FailOnErrors: failOnErrors,
+		Operations: []*models.BulkOperation{
+			{
+				Method:       models.BulkMethodPost,
+				ResourceType: models.BulkResourceUsers,
+				BulkID:       "user",
+				Data:         models.CreateUser{UserName: "user@example.com", GivenName: "User", FamilyName: "Name"},
+			},
+			{
+				Method:       models.BulkMethodPost,
+				ResourceType: models.BulkResourceGroups,
+				BulkID:       "group",
+				Data: models.CreateGroupBody{
+					DisplayName: "Group",
+					Members:     []models.GroupMember{{ID: models.BulkIDRef("user"), Email: "user@example.com"}},
+				},
+			},
+			{
+				Method:       models.BulkMethodPatch,
+				ResourceType: models.BulkResourceGroups,
+				ID:           models.BulkIDRef("group"),
+				Data:         models.UpdateGroupReplaceName{DisplayName: "Renamed Group"},
+			},
+		},
+	}
+}
+
+func mockedApiExecuteWithBulk(config string, requests *[]*service.BulkRequest) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		path := request.URL.Path
+		switch {
+		case strings.HasSuffix(path, "/ServiceProviderConfig"):
+			return newMockBulkResponse(http.StatusOK, config), nil
+		case strings.HasSuffix(path, "/Bulk"):
+			bulkRequest := &service.BulkRequest{}
+			json.NewDecoder(request.Body).Decode(bulkRequest)
+			*requests = append(*requests, bulkRequest)
+			operations := []string{}
+			for _, operation := range bulkRequest.Operations {
+				switch operation.Method {
+				case http.MethodPost:
+					location := fmt.Sprintf("https://example.com%s/%s-id", operation.Path, operation.BulkID)
+					operations = append(operations, fmt.Sprintf(`{"method": "POST", "bulkId": %q, "location": %q, "status": "201"}`, operation.BulkID, location))
+				default:
+					operations = append(operations, fmt.Sprintf(`{"method": %q, "status": {"code": "200"}}`, operation.Method))
+				}
+			}
+			return newMockBulkResponse(http.StatusOK, fmt.Sprintf(`{"Operations": [%s]}`, strings.Join(operations, ","))), nil
+		case request.Method == http.MethodPost && strings.HasSuffix(path, "/Users"):
+			return newMockBulkResponse(http.StatusCreated, `{"id": "user-id"}`), nil
+		case request.Method == http.MethodPost && strings.HasSuffix(path, "/Groups"):
+			return newMockBulkResponse(http.StatusCreated, `{"id": "group-id", "meta": {"location": "https://example.com/Groups/group-id"}}`), nil
+		}
+		return newMockBulkResponse(http.StatusNoContent, ``), nil
+	}
+}
+
+func newMockBulkResponse(statusCode int, body string) *http.Response {
+	return &http.Response{StatusCode: statusCode, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(body))}
+}
+
+func TestMatchBulkOperationResponses(t *testing.T) {
+	t.Run("should match the operations by their bulk id, location or index", func(t *testing.T) {
+		batch := []*bulkOperation{
+			{method: http.MethodPost, bulkID: "user", pathname: "Users"},
+			{method: http.MethodPatch, pathname: "Groups", id: "first-id"},
+			{method: http.MethodPatch, pathname: "Groups", id: "second-id"},
+			{method: http.MethodDelete, pathname: "Users", id: "user-id"},
+		}
+		responses := []*service.BulkOperationResponse{
+			{Method: http.MethodPatch, Location: "https://example.com/v2/Groups/second-id"},
+			{Method: http.MethodPatch, Location: "https://example.com/v2/Groups/first-id"},
+			{Method: http.MethodPost, BulkID: "user", Location: "https://example.com/v2/Users/user-id"},
+			{Method: http.MethodDelete},
+		}
+		matches := matchBulkOperationResponses(batch, responses)
+		assertT := assert.New(t)
+
+		assertT.Equal([]*service.BulkOperationResponse{responses[2], responses[1], responses[0], responses[3]}, matches)
+	})
+
+	t.Run("should not match the responses of unknown operations", func(t *testing.T) {
+		batch := []*bulkOperation{
+			{method: http.MethodPost, bulkID: "user", pathname: "Users"},
+			{method: http.MethodDelete, pathname: "Users", id: "user-id"},
+		}
+		responses := []*service.BulkOperationResponse{
+			{Method: http.MethodPost, BulkID: "other"},
+		}
+		matches := matchBulkOperationResponses(batch, responses)
+
+		assert.Equal(t, []*service.BulkOperationResponse{nil, nil}, matches)
+	})
+}
+
+func TestBulkOperationBulkIDRefs(t *testing.T) {
+	t.Run("should resolve the references of the members and manager", func(t *testing.T) {
+		groupOperation, err := convertPorcelainToBulkOperation(&models.BulkOperation{
+			Method:       models.BulkMethodPatch,
+			ResourceType: models.BulkResourceGroups,
+			ID:           models.BulkIDRef("group"),
+			Data:         models.UpdateGroupAddMembers{Members: []models.GroupMember{{ID: models.BulkIDRef("user"), Email: "user@example.com"}}},
+		})
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.True(groupOperation.hasBulkIDRef())
+		groupOperation.resolveBulkIDs(map[string]string{"group": "group-id", "user": "user-id"})
+		assertT.False(groupOperation.hasBulkIDRef())
+		assertT.Equal("/Groups/group-id", groupOperation.getPath())
+		assertT.Contains(string(groupOperation.data), `"value":"user-id"`)
+
+		userOperation := &bulkOperation{
+			method:   http.MethodPost,
+			bulkID:   "employee",
+			pathname: "Users",
+			data:     json.RawMessage(`{"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"manager": {"value": "bulkId:manager"}}}`),
+		}
+		assertT.True(userOperation.hasBulkIDRef())
+		userOperation.resolveBulkIDs(map[string]string{"manager": "manager-id"})
+		assertT.Contains(string(userOperation.data), `"manager":{"value":"manager-id"}`)
+	})
+
+	t.Run("should not treat the other attributes starting with bulkId: as references", func(t *testing.T) {
+		operation, err := convertPorcelainToBulkOperation(&models.BulkOperation{
+			Method:       models.BulkMethodPost,
+			ResourceType: models.BulkResourceGroups,
+			BulkID:       "group",
+			Data:         models.CreateGroupBody{DisplayName: models.BulkIDRef("user")},
+		})
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.False(operation.hasBulkIDRef())
+		operation.resolveBulkIDs(map[string]string{"user": "user-id"})
+		assertT.Contains(string(operation.data), `"displayName":"bulkId:user"`)
+
+		patchOperation, err := convertPorcelainToBulkOperation(&models.BulkOperation{
+			Method:       models.BulkMethodPatch,
+			ResourceType: models.BulkResourceGroups,
+			ID:           "group-id",
+			Data:         models.UpdateGroupReplaceName{DisplayName: models.BulkIDRef("user")},
+		})
+		assertT.Nil(err)
+		assertT.False(patchOperation.hasBulkIDRef())
+	})
+}
@@ -0,0 +1,49 @@
+package module
+
+import (
+	"context"
+
+	"github.com/strongdm/scimsdk/internal/service"
+	"github.com/strongdm/scimsdk/models"
+)
+
+type discoveryModuleImpl struct {
+	service               service.DiscoveryService
+	providedURL           string
+	instrumentation       Instrumentation
+	serviceProviderConfig *ServiceProviderConfigLoader
+}
+
+func NewDiscoveryModule(service service.DiscoveryService, providedURL string, instrumentation Instrumentation, serviceProviderConfig *ServiceProviderConfigLoader) *discoveryModuleImpl {
+	return &discoveryModuleImpl{service, providedURL, instrumentation, serviceProviderConfig}
+}
+
+func (module *discoveryModuleImpl) ServiceProviderConfig(ctx context.Context) (_ *models.ServiceProviderConfig, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, discoveryOperationPrefix, "ServiceProviderConfig", "")
+	defer endSpan(span, &err)
+	response, err := module.serviceProviderConfig.Load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return convertServiceProviderConfigResponseToPorcelain(response), nil
+}
+
+func (module *discoveryModuleImpl) ResourceTypes(ctx context.Context) (_ []*models.ResourceType, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, discoveryOperationPrefix, "ResourceTypes", "")
+	defer endSpan(span, &err)
+	response, err := module.service.FindResourceTypes(ctx, &service.FindOptions{BaseAPIURL: module.providedURL})
+	if err != nil {
+		return nil, err
+	}
+	return convertResourceTypeResponseListToPorcelain(response), nil
+}
+
+func (module *discoveryModuleImpl) Schemas(ctx context.Context) (_ []*models.Schema, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, discoveryOperationPrefix, "Schemas", "")
+	defer endSpan(span, &err)
+	response, err := module.service.FindSchemas(ctx, &service.FindOptions{BaseAPIURL: module.providedURL})
+	if err != nil {
+		return nil, err
+	}
+	return convertSchemaResponseListToPorcelain(response), nil
+}
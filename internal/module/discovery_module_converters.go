@@ -0,0 +1,105 @@
+package module
+
+import (
+	"github.com/strongdm/scimsdk/internal/service"
+	"github.com/strongdm/scimsdk/models"
+)
+
+func convertServiceProviderConfigResponseToPorcelain(response *service.ServiceProviderConfigResponse) *models.ServiceProviderConfig {
+	config := &models.ServiceProviderConfig{
+		DocumentationURI:      response.DocumentationURI,
+		Patch:                 convertSupportedFeatureResponseToPorcelain(response.Patch),
+		ChangePassword:        convertSupportedFeatureResponseToPorcelain(response.ChangePassword),
+		Sort:                  convertSupportedFeatureResponseToPorcelain(response.Sort),
+		ETag:                  convertSupportedFeatureResponseToPorcelain(response.ETag),
+		AuthenticationSchemes: []models.AuthenticationScheme{},
+	}
+	if response.Bulk != nil {
+		config.Bulk = models.BulkSupport{
+			Supported:      response.Bulk.Supported,
+			MaxOperations:  response.Bulk.MaxOperations,
+			MaxPayloadSize: response.Bulk.MaxPayloadSize,
+		}
+	}
+	if response.Filter != nil {
+		config.Filter = models.FilterSupport{
+			Supported:  response.Filter.Supported,
+			MaxResults: response.Filter.MaxResults,
+		}
+	}
+	for _, scheme := range response.AuthenticationSchemes {
+		if scheme != nil {
+			config.AuthenticationSchemes = append(config.AuthenticationSchemes, models.AuthenticationScheme(*scheme))
+		}
+	}
+	return config
+}
+
+func convertSupportedFeatureResponseToPorcelain(response *service.SupportedFeatureResponse) models.FeatureSupport {
+	if response == nil {
+		return models.FeatureSupport{}
+	}
+	return models.FeatureSupport{Supported: response.Supported}
+}
+
+func convertResourceTypeResponseListToPorcelain(responses []*service.ResourceTypeResponse) []*models.ResourceType {
+	resourceTypes := []*models.ResourceType{}
+	for _, response := range responses {
+		if response == nil {
+			continue
+		}
+		resourceType := &models.ResourceType{
+			ID:               response.ID,
+			Name:             response.Name,
+			Description:      response.Description,
+			Endpoint:         response.Endpoint,
+			Schema:           response.Schema,
+			SchemaExtensions: []models.SchemaExtension{},
+		}
+		for _, extension := range response.SchemaExtensions {
+			if extension != nil {
+				resourceType.SchemaExtensions = append(resourceType.SchemaExtensions, models.SchemaExtension(*extension))
+			}
+		}
+		resourceTypes = append(resourceTypes, resourceType)
+	}
+	return resourceTypes
+}
+
+func convertSchemaResponseListToPorcelain(responses []*service.SchemaResponse) []*models.Schema {
+	schemas := []*models.Schema{}
+	for _, response := range responses {
+		if response != nil {
+			schemas = append(schemas, &models.Schema{
+				ID:          response.ID,
+				Name:        response.Name,
+				Description: response.Description,
+				Attributes:  convertSchemaAttributeResponseListToPorcelain(response.Attributes),
+			})
+		}
+	}
+	return schemas
+}
+
+func convertSchemaAttributeResponseListToPorcelain(responses []*service.SchemaAttributeResponse) []*models.SchemaAttribute {
+	attributes := []*models.SchemaAttribute{}
+	for _, response := range responses {
+		if response != nil {
+			attributes = append(attributes, &models.SchemaAttribute{
+				Name:            response.Name,
+				Type:            response.Type,
+				MultiValued:     response.MultiValued,
+				Description:     response.Description,
+				Required:        response.Required,
+				CanonicalValues: response.CanonicalValues,
+				CaseExact:       response.CaseExact,
+				Mutability:      response.Mutability,
+				Returned:        response.Returned,
+				Uniqueness:      response.Uniqueness,
+				ReferenceTypes:  response.ReferenceTypes,
+				SubAttributes:   convertSchemaAttributeResponseListToPorcelain(response.SubAttributes),
+			})
+		}
+	}
+	return attributes
+}
@@ -0,0 +1,45 @@
+package module
+
+import (
+	"testing"
+
+	"github.com/strongdm/scimsdk/internal/service"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertServiceProviderConfigResponseToPorcelain(t *testing.T) {
+	t.Run("should convert the supported features", func(t *testing.T) {
+		response := &service.ServiceProviderConfigResponse{
+			Patch:  &service.SupportedFeatureResponse{Supported: true},
+			Filter: &service.FilterFeatureResponse{Supported: true, MaxResults: 200},
+		}
+		config := convertServiceProviderConfigResponseToPorcelain(response)
+		assertT := assert.New(t)
+
+		assertT.True(config.Patch.Supported)
+		assertT.True(config.Filter.Supported)
+		assertT.Equal(200, config.Filter.MaxResults)
+		assertT.False(config.Bulk.Supported)
+		assertT.False(config.ETag.Supported)
+	})
+}
+
+func TestConvertSchemaResponseListToPorcelain(t *testing.T) {
+	t.Run("should convert the schema attributes recursively", func(t *testing.T) {
+		response := []*service.SchemaResponse{{
+			ID: "urn:ietf:params:scim:schemas:core:2.0:User",
+			Attributes: []*service.SchemaAttributeResponse{{
+				Name:          "emails",
+				MultiValued:   true,
+				SubAttributes: []*service.SchemaAttributeResponse{{Name: "value"}},
+			}},
+		}}
+		schemas := convertSchemaResponseListToPorcelain(response)
+		assertT := assert.New(t)
+
+		assertT.Len(schemas, 1)
+		assertT.True(schemas[0].Attributes[0].MultiValued)
+		assertT.Equal("value", schemas[0].Attributes[0].SubAttributes[0].Name)
+	})
+}
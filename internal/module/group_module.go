@@ -2,21 +2,27 @@ package module
 
 import (
 	"context"
+	"errors"
 
+	"github.com/strongdm/scimsdk/filter"
 	"github.com/strongdm/scimsdk/internal/service"
 	"github.com/strongdm/scimsdk/models"
 )
 
 type groupModuleImpl struct {
-	service     service.GroupService
-	providedURL string
+	service               service.GroupService
+	providedURL           string
+	instrumentation       Instrumentation
+	serviceProviderConfig *ServiceProviderConfigLoader
 }
 
-func NewGroupModule(service service.GroupService, providedURL string) *groupModuleImpl {
-	return &groupModuleImpl{service, providedURL}
+func NewGroupModule(service service.GroupService, providedURL string, instrumentation Instrumentation, serviceProviderConfig *ServiceProviderConfigLoader) *groupModuleImpl {
+	return &groupModuleImpl{service, providedURL, instrumentation, serviceProviderConfig}
 }
 
-func (module *groupModuleImpl) Create(ctx context.Context, group models.CreateGroupBody) (*models.Group, error) {
+func (module *groupModuleImpl) Create(ctx context.Context, group models.CreateGroupBody) (_ *models.Group, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, groupsOperationPrefix, "Create", "")
+	defer endSpan(span, &err)
 	body, err := convertPorcelainToCreateGroupRequest(&group)
 	if err != nil {
 		return nil, err
@@ -30,11 +36,19 @@ func (module *groupModuleImpl) Create(ctx context.Context, group models.CreateGr
 }
 
 func (module *groupModuleImpl) List(ctx context.Context, paginationOptions *models.PaginationOptions) models.Iterator[models.Group] {
-	return newIterator(module.iteratorMiddleware(ctx), paginationOptions)
+	ctx = withOperation(ctx, groupsOperationPrefix, "List", "")
+	fetchFn := withClientSideSort(ctx, module.serviceProviderConfig, module.iteratorMiddleware(ctx))
+	return newIterator(ctx, fetchFn, paginationOptions, module.instrumentation)
 }
 
 func (module *groupModuleImpl) Find(ctx context.Context, id string) (*models.Group, error) {
-	opts, err := newServiceFindOptions(id, module.providedURL)
+	return module.FindWithOptions(ctx, id, nil)
+}
+
+func (module *groupModuleImpl) FindWithOptions(ctx context.Context, id string, findOpts *models.FindOptions) (_ *models.Group, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, groupsOperationPrefix, "Find", id)
+	defer endSpan(span, &err)
+	opts, err := newServiceFindOptions(id, findOpts, module.providedURL)
 	if err != nil {
 		return nil, err
 	}
@@ -45,12 +59,23 @@ func (module *groupModuleImpl) Find(ctx context.Context, id string) (*models.Gro
 	return convertGroupResponseToPorcelain(response), nil
 }
 
-func (module *groupModuleImpl) Replace(ctx context.Context, id string, group models.ReplaceGroupBody) (*models.Group, error) {
+func (module *groupModuleImpl) FindByExternalID(ctx context.Context, externalID string) (_ *models.Group, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, groupsOperationPrefix, "FindByExternalID", "")
+	defer endSpan(span, &err)
+	if externalID == "" {
+		return nil, errors.New("you must pass the group external id")
+	}
+	return findByExternalID(module.List(ctx, newExternalIDPaginationOptions(externalID)), "group", externalID)
+}
+
+func (module *groupModuleImpl) Replace(ctx context.Context, id string, group models.ReplaceGroupBody, writeOpts ...*models.WriteOptions) (_ *models.Group, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, groupsOperationPrefix, "Replace", id)
+	defer endSpan(span, &err)
 	body, err := convertPorcelainToReplaceGroupRequest(&group)
 	if err != nil {
 		return nil, err
 	}
-	opts, err := newServiceReplaceOptions(id, body, module.providedURL)
+	opts, err := newServiceReplaceOptions(id, body, module.providedURL, writeOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -61,56 +86,108 @@ func (module *groupModuleImpl) Replace(ctx context.Context, id string, group mod
 	return convertGroupResponseToPorcelain(response), nil
 }
 
-func (module *groupModuleImpl) UpdateAddMembers(ctx context.Context, id string, members []models.GroupMember) (bool, error) {
+func (module *groupModuleImpl) UpdateAddMembers(ctx context.Context, id string, members []models.GroupMember, writeOpts ...*models.WriteOptions) (_ bool, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, groupsOperationPrefix, "UpdateAddMembers", id)
+	defer endSpan(span, &err)
 	body, err := convertPorcelainToUpdateGroupAddMembersRequest(members)
 	if err != nil {
 		return false, err
 	}
-	opts, err := newServiceUpdateOptions(id, body, module.providedURL)
+	opts, err := newServiceUpdateOptions(id, body, module.providedURL, writeOpts...)
 	if err != nil {
 		return false, err
 	}
 	return module.service.Update(ctx, opts)
 }
 
-func (module *groupModuleImpl) UpdateReplaceMembers(ctx context.Context, id string, members []models.GroupMember) (bool, error) {
+func (module *groupModuleImpl) UpdateReplaceMembers(ctx context.Context, id string, members []models.GroupMember, writeOpts ...*models.WriteOptions) (_ bool, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, groupsOperationPrefix, "UpdateReplaceMembers", id)
+	defer endSpan(span, &err)
 	body, err := convertPorcelainToUpdateGroupReplaceMembersRequest(members)
 	if err != nil {
 		return false, err
 	}
-	opts, err := newServiceUpdateOptions(id, body, module.providedURL)
+	opts, err := newServiceUpdateOptions(id, body, module.providedURL, writeOpts...)
 	if err != nil {
 		return false, err
 	}
 	return module.service.Update(ctx, opts)
 }
 
-func (module *groupModuleImpl) UpdateReplaceName(ctx context.Context, id string, replaceName models.UpdateGroupReplaceName) (bool, error) {
+func (module *groupModuleImpl) UpdateReplaceName(ctx context.Context, id string, replaceName models.UpdateGroupReplaceName, writeOpts ...*models.WriteOptions) (_ bool, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, groupsOperationPrefix, "UpdateReplaceName", id)
+	defer endSpan(span, &err)
 	body, err := convertPorcelainToUpdateGroupNameRequest(replaceName)
 	if err != nil {
 		return false, err
 	}
-	opts, err := newServiceUpdateOptions(id, body, module.providedURL)
+	opts, err := newServiceUpdateOptions(id, body, module.providedURL, writeOpts...)
 	if err != nil {
 		return false, err
 	}
 	return module.service.Update(ctx, opts)
 }
 
-func (module *groupModuleImpl) UpdateRemoveMemberByID(ctx context.Context, id string, memberID string) (bool, error) {
+func (module *groupModuleImpl) UpdateRemoveMemberByID(ctx context.Context, id string, memberID string, writeOpts ...*models.WriteOptions) (_ bool, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, groupsOperationPrefix, "UpdateRemoveMemberByID", id)
+	defer endSpan(span, &err)
 	body, err := convertPorcelainToUpdateGroupRemoveMemberRequest(memberID)
 	if err != nil {
 		return false, err
 	}
-	opts, err := newServiceUpdateOptions(id, body, module.providedURL)
+	opts, err := newServiceUpdateOptions(id, body, module.providedURL, writeOpts...)
 	if err != nil {
 		return false, err
 	}
 	return module.service.Update(ctx, opts)
 }
 
-func (module *groupModuleImpl) Delete(ctx context.Context, id string) (bool, error) {
-	opts, err := newServiceDeleteOptions(id, module.providedURL)
+func (module *groupModuleImpl) UpdateRemoveMembers(ctx context.Context, id string, membersFilter filter.Filter, writeOpts ...*models.WriteOptions) (_ bool, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, groupsOperationPrefix, "UpdateRemoveMembers", id)
+	defer endSpan(span, &err)
+	body, err := convertPorcelainToUpdateGroupRemoveMembersRequest(membersFilter)
+	if err != nil {
+		return false, err
+	}
+	opts, err := newServiceUpdateOptions(id, body, module.providedURL, writeOpts...)
+	if err != nil {
+		return false, err
+	}
+	return module.service.Update(ctx, opts)
+}
+
+// Patch applies every operation in a single request and returns the updated
+// group, finding it when the server doesn't return it.
+func (module *groupModuleImpl) Patch(ctx context.Context, id string, patch *models.GroupPatch, writeOpts ...*models.WriteOptions) (_ *models.Group, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, groupsOperationPrefix, "Patch", id)
+	defer endSpan(span, &err)
+	body, err := convertPorcelainToPatchGroupRequest(patch)
+	if err != nil {
+		return nil, err
+	}
+	opts, err := newServiceUpdateOptions(id, body, module.providedURL, writeOpts...)
+	if err != nil {
+		return nil, err
+	}
+	response, err := module.service.Patch(ctx, opts)
+	if err != nil {
+		return nil, err
+	} else if response == nil {
+		findOpts, err := newServiceFindOptions(id, nil, module.providedURL)
+		if err != nil {
+			return nil, err
+		}
+		if response, err = module.service.Find(ctx, findOpts); err != nil {
+			return nil, err
+		}
+	}
+	return convertGroupResponseToPorcelain(response), nil
+}
+
+func (module *groupModuleImpl) Delete(ctx context.Context, id string, writeOpts ...*models.WriteOptions) (_ bool, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, groupsOperationPrefix, "Delete", id)
+	defer endSpan(span, &err)
+	opts, err := newServiceDeleteOptions(id, module.providedURL, writeOpts...)
 	if err != nil {
 		return false, err
 	}
@@ -118,7 +195,9 @@ func (module *groupModuleImpl) Delete(ctx context.Context, id string) (bool, err
 }
 
 func (module *groupModuleImpl) iteratorMiddleware(ctx context.Context) iteratorFetchFunc[models.Group] {
-	return func(opts *models.PaginationOptions) ([]*models.Group, bool, error) {
+	return func(opts *models.PaginationOptions) (_ []*models.Group, _ bool, err error) {
+		ctx, span := module.instrumentation.startOperation(ctx, groupsOperationPrefix, "List", "", getPaginationAttributes(opts)...)
+		defer endSpan(span, &err)
 		listOpts, err := newServiceListOptions(opts, module.providedURL)
 		if err != nil {
 			return nil, false, err
@@ -2,8 +2,8 @@ package module
 
 import (
 	"errors"
-	"fmt"
 
+	"github.com/strongdm/scimsdk/filter"
 	"github.com/strongdm/scimsdk/internal/service"
 	"github.com/strongdm/scimsdk/models"
 )
@@ -19,16 +19,23 @@ func convertGroupResponseListToPorcelain(groupListResponse []*service.GroupRespo
 func convertGroupResponseToPorcelain(groupResponse *service.GroupResponse) *models.Group {
 	return &models.Group{
 		ID:          groupResponse.ID,
+		ExternalID:  groupResponse.ExternalID,
 		DisplayName: groupResponse.DisplayName,
 		Members:     convertGroupMemberResponseListToPorcelain(groupResponse.Members),
-		Meta:        convertGroupMetaResponseToPorcelain(groupResponse.Meta),
+		Meta:        convertMetadataResponseToPorcelain(groupResponse.Meta),
+		Extensions:  convertExtensionsResponseToPorcelain(groupResponse.Extensions),
 	}
 }
 
 func convertGroupMemberResponseListToPorcelain(memberListResponse []*service.GroupMemberResponse) []*models.GroupMember {
+	if memberListResponse == nil {
+		return nil
+	}
 	memberList := make([]*models.GroupMember, 0)
 	for _, memberResponse := range memberListResponse {
-		memberList = append(memberList, convertGroupMemberResponseToPorcelain(memberResponse))
+		if memberResponse != nil {
+			memberList = append(memberList, convertGroupMemberResponseToPorcelain(memberResponse))
+		}
 	}
 	return memberList
 }
@@ -40,13 +47,6 @@ func convertGroupMemberResponseToPorcelain(memberResponse *service.GroupMemberRe
 	}
 }
 
-func convertGroupMetaResponseToPorcelain(metaResponse *service.GroupMetadataResponse) *models.GroupMetadata {
-	return &models.GroupMetadata{
-		ResourceType: metaResponse.ResourceType,
-		Location:     metaResponse.Location,
-	}
-}
-
 func convertPorcelainToCreateGroupRequest(group *models.CreateGroupBody) (*service.CreateGroupRequest, error) {
 	if group.DisplayName == "" {
 		return nil, errors.New("you must pass the group display name in DisplayName field")
@@ -56,9 +56,11 @@ func convertPorcelainToCreateGroupRequest(group *models.CreateGroupBody) (*servi
 		return nil, err
 	}
 	return &service.CreateGroupRequest{
-		Schemas:     []string{defaultGroupSchema},
+		Schemas:     getSchemas(defaultGroupSchema, group.Extensions),
+		ExternalID:  group.ExternalID,
 		DisplayName: group.DisplayName,
 		Members:     members,
+		Extensions:  group.Extensions,
 	}, nil
 }
 
@@ -71,9 +73,11 @@ func convertPorcelainToReplaceGroupRequest(group *models.ReplaceGroupBody) (*ser
 		return nil, err
 	}
 	return &service.ReplaceGroupRequest{
-		Schemas:     []string{defaultGroupSchema},
+		Schemas:     getSchemas(defaultGroupSchema, group.Extensions),
+		ExternalID:  group.ExternalID,
 		DisplayName: group.DisplayName,
 		Members:     members,
+		Extensions:  group.Extensions,
 	}, nil
 }
 
@@ -164,13 +168,27 @@ func convertPorcelainToUpdateGroupRemoveMemberRequest(memberID string) (*service
 	if memberID == "" {
 		return nil, errors.New("you must pass the member id in memberID field")
 	}
+	return convertPorcelainToUpdateGroupRemoveMembersRequest(filter.Eq("value", memberID))
+}
+
+func convertPorcelainToUpdateGroupRemoveMembersRequest(membersFilter filter.Filter) (*service.UpdateGroupRequest, error) {
+	if membersFilter == nil {
+		return nil, errors.New("you must pass the members filter")
+	}
 	return &service.UpdateGroupRequest{
 		Schemas: []string{defaultPatchSchema},
 		Operations: []interface{}{
 			&service.UpdateGroupOperationRequest{
 				OP:   "remove",
-				Path: fmt.Sprintf("members[value eq \"%s\"]", memberID),
+				Path: filter.Values("members", membersFilter).String(),
 			},
 		},
 	}, nil
 }
+
+func convertPorcelainToPatchGroupRequest(patch *models.GroupPatch) (*service.PatchRequest, error) {
+	if patch == nil {
+		return nil, errors.New("you must pass the group patch")
+	}
+	return convertPorcelainToPatchRequest(patch.Operations)
+}
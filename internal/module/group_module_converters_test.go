@@ -3,6 +3,7 @@ package module
 import (
 	"testing"
 
+	"github.com/strongdm/scimsdk/filter"
 	"github.com/strongdm/scimsdk/internal/service"
 	"github.com/strongdm/scimsdk/models"
 
@@ -10,6 +11,16 @@ import (
 )
 
 func TestConvertGroupToAndFromPorcelain(t *testing.T) {
+	t.Run("should convert a partial group response without members and meta", func(t *testing.T) {
+		group := convertGroupResponseToPorcelain(&service.GroupResponse{ID: "xxx", DisplayName: "yyy"})
+		assertT := assert.New(t)
+
+		assertT.Equal("xxx", group.ID)
+		assertT.Equal("yyy", group.DisplayName)
+		assertT.Nil(group.Members)
+		assertT.Nil(group.Meta)
+	})
+
 	t.Run("should convert a replace group body to api body when passing a valid replace group body", func(t *testing.T) {
 		body := getValidReplaceGroup()
 		apiBody, err := convertPorcelainToReplaceGroupRequest(body)
@@ -157,6 +168,25 @@ func TestConvertGroupToAndFromPorcelain(t *testing.T) {
 		assertT.Contains(operation.Path, memberID)
 	})
 
+	t.Run("should escape the member id in the remove member path", func(t *testing.T) {
+		apiBody, err := convertPorcelainToUpdateGroupRemoveMemberRequest(`xxx" or value pr or value eq "yyy`)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		operation := apiBody.Operations[0].(*service.UpdateGroupOperationRequest)
+		assertT.Equal(`members[value eq "xxx\" or value pr or value eq \"yyy"]`, operation.Path)
+	})
+
+	t.Run("should convert a members filter to api group remove members body", func(t *testing.T) {
+		apiBody, err := convertPorcelainToUpdateGroupRemoveMembersRequest(filter.Or(filter.Eq("value", "xxx"), filter.Eq("value", "yyy")))
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		operation := apiBody.Operations[0].(*service.UpdateGroupOperationRequest)
+		assertT.Equal("remove", operation.OP)
+		assertT.Equal(`members[value eq "xxx" or value eq "yyy"]`, operation.Path)
+	})
+
 	t.Run("should return an error when passing an empty member id to api group remove member body", func(t *testing.T) {
 		_, err := convertPorcelainToUpdateGroupRemoveMemberRequest("")
 		assertT := assert.New(t)
@@ -189,3 +219,32 @@ func getValidReplaceGroup() *models.ReplaceGroupBody {
 		},
 	}
 }
+
+func TestConvertPorcelainToPatchGroupRequest(t *testing.T) {
+	t.Run("should convert every operation of the group patch", func(t *testing.T) {
+		patch := models.NewGroupPatch().
+			ReplaceDisplayName("Engineering").
+			AddMembers(models.GroupMember{ID: "new-member"}).
+			RemoveMembersByID("old-member", "other-member").
+			ReplaceExternalID("eng")
+		request, err := convertPorcelainToPatchGroupRequest(patch)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Len(request.Operations, 4)
+		assertT.Equal("displayName", request.Operations[0].Path)
+		assertT.JSONEq(`"Engineering"`, string(request.Operations[0].Value))
+		assertT.Equal("add", request.Operations[1].OP)
+		assertT.JSONEq(`[{"value": "new-member"}]`, string(request.Operations[1].Value))
+		assertT.Equal("remove", request.Operations[2].OP)
+		assertT.Equal(`members[value eq "old-member" or value eq "other-member"]`, request.Operations[2].Path)
+		assertT.Equal("externalId", request.Operations[3].Path)
+	})
+
+	t.Run("should replace the members with an empty list when no members are passed", func(t *testing.T) {
+		request, err := convertPorcelainToPatchGroupRequest(models.NewGroupPatch().ReplaceMembers())
+
+		assert.Nil(t, err)
+		assert.JSONEq(t, `[]`, string(request.Operations[0].Value))
+	})
+}
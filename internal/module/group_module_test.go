@@ -3,16 +3,25 @@ package module
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
+	"net/url"
 	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/strongdm/scimsdk/filter"
+	"github.com/strongdm/scimsdk/internal/api"
 	"github.com/strongdm/scimsdk/internal/service"
 	"github.com/strongdm/scimsdk/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const mockGroupsPageSize = 2
@@ -20,7 +29,7 @@ const mockGroupsPageSize = 2
 func TestGroupServiceListIterator(t *testing.T) {
 	t.Run("should return a groups iterator when there's no pagination options", func(t *testing.T) {
 		mockApi := getMockedAPI(mockedApiExecuteWithGroupPageResponse)
-		serviceApi := service.NewGroupService(mockApi, "token")
+		serviceApi := service.NewGroupService(mockApi, api.NewStaticTokenSource("token"))
 		module := NewMockGroupModule(serviceApi)
 		iterator := module.List(context.Background(), nil)
 		assertT := assert.New(t)
@@ -39,7 +48,7 @@ func TestGroupServiceListIterator(t *testing.T) {
 
 	t.Run("should return a groups iterator when there's pagination options", func(t *testing.T) {
 		mockApi := getMockedAPI(mockedApiExecuteWithGroupPageResponse)
-		serviceApi := service.NewGroupService(mockApi, "token")
+		serviceApi := service.NewGroupService(mockApi, api.NewStaticTokenSource("token"))
 		module := NewMockGroupModule(serviceApi)
 		opts := &models.PaginationOptions{PageSize: mockGroupsPageSize, Offset: 1}
 		iterator := module.List(context.Background(), opts)
@@ -58,9 +67,68 @@ func TestGroupServiceListIterator(t *testing.T) {
 		assertT.True(iterator.IsEmpty())
 	})
 
+	t.Run("should send the filter expression as the filter query param", func(t *testing.T) {
+		var query string
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			query = request.URL.Query().Get("filter")
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"Resources": []}`))}, nil
+		})
+		serviceApi := service.NewGroupService(mockApi, api.NewStaticTokenSource("token"))
+		module := NewMockGroupModule(serviceApi)
+		opts := &models.PaginationOptions{FilterExpression: filter.Sw("displayName", `x" or displayName pr`)}
+		iterator := module.List(context.Background(), opts)
+		assertT := assert.New(t)
+
+		assertT.False(iterator.Next())
+		assertT.Nil(iterator.Err())
+		assertT.Equal(`displayName sw "x\" or displayName pr"`, query)
+	})
+
+	t.Run("should request only the passed attributes of the groups", func(t *testing.T) {
+		var query url.Values
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			query = request.URL.Query()
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"Resources": [{"id": "xxx", "displayName": "yyy"}], "itemsPerPage": 2}`))}, nil
+		})
+		serviceApi := service.NewGroupService(mockApi, api.NewStaticTokenSource("token"))
+		module := NewMockGroupModule(serviceApi)
+		iterator := module.List(context.Background(), &models.PaginationOptions{PageSize: 2, Attributes: []string{"id", "displayName"}})
+		assertT := assert.New(t)
+
+		assertT.True(iterator.Next())
+		assertT.Nil(iterator.Err())
+		assertT.Equal("yyy", iterator.Value().DisplayName)
+		assertT.Nil(iterator.Value().Members)
+		assertT.Equal("id,displayName", query.Get("attributes"))
+	})
+
+	t.Run("should return an error when passing both the attributes and the excluded attributes", func(t *testing.T) {
+		mockApi := getMockedAPI(mockedApiExecuteWithGroupPageResponse)
+		serviceApi := service.NewGroupService(mockApi, api.NewStaticTokenSource("token"))
+		module := NewMockGroupModule(serviceApi)
+		iterator := module.List(context.Background(), &models.PaginationOptions{Attributes: []string{"id"}, ExcludedAttributes: []string{"members"}})
+		assertT := assert.New(t)
+
+		assertT.False(iterator.Next())
+		assertT.Contains(iterator.Err().Error(), "either the Attributes or the ExcludedAttributes")
+	})
+
+	t.Run("should return an error when passing both the filter and the filter expression", func(t *testing.T) {
+		mockApi := getMockedAPI(mockedApiExecuteWithGroupPageResponse)
+		serviceApi := service.NewGroupService(mockApi, api.NewStaticTokenSource("token"))
+		module := NewMockGroupModule(serviceApi)
+		opts := &models.PaginationOptions{Filter: "displayName pr", FilterExpression: filter.Pr("displayName")}
+		iterator := module.List(context.Background(), opts)
+		assertT := assert.New(t)
+
+		assertT.False(iterator.Next())
+		assertT.NotNil(iterator.Err())
+		assertT.Contains(iterator.Err().Error(), "either the Filter or the FilterExpression")
+	})
+
 	t.Run("should return an empty groups iterator iterator when the offset is greater than page size and the groups count", func(t *testing.T) {
 		mockApi := getMockedAPI(mockedApiExecuteWithGroupPageResponse)
-		serviceApi := service.NewGroupService(mockApi, "token")
+		serviceApi := service.NewGroupService(mockApi, api.NewStaticTokenSource("token"))
 		module := NewMockGroupModule(serviceApi)
 		opts := &models.PaginationOptions{PageSize: mockGroupsPageSize, Offset: 3}
 		iterator := module.List(context.Background(), opts)
@@ -75,6 +143,205 @@ func TestGroupServiceListIterator(t *testing.T) {
 	})
 }
 
+func TestGroupModuleFindWithOptions(t *testing.T) {
+	t.Run("should find a group without the excluded attributes", func(t *testing.T) {
+		var query url.Values
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			query = request.URL.Query()
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"id": "xxx", "displayName": "yyy"}`))}, nil
+		})
+		serviceApi := service.NewGroupService(mockApi, api.NewStaticTokenSource("token"))
+		module := NewMockGroupModule(serviceApi)
+		group, err := module.FindWithOptions(context.Background(), "xxx", &models.FindOptions{ExcludedAttributes: []string{"members"}})
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("yyy", group.DisplayName)
+		assertT.Nil(group.Members)
+		assertT.Equal("members", query.Get("excludedAttributes"))
+	})
+}
+
+func TestGroupModuleFindByExternalID(t *testing.T) {
+	t.Run("should find the group filtered by its external id", func(t *testing.T) {
+		var query url.Values
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			query = request.URL.Query()
+			body := `{"Resources": [{"id": "xxx", "externalId": "eng", "displayName": "Engineering"}], "itemsPerPage": 2, "totalResults": 1}`
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		})
+		module := NewMockGroupModule(service.NewGroupService(mockApi, api.NewStaticTokenSource("token")))
+		group, err := module.FindByExternalID(context.Background(), "eng")
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("xxx", group.ID)
+		assertT.Equal("eng", group.ExternalID)
+		assertT.Equal(`externalId eq "eng"`, query.Get("filter"))
+	})
+}
+
+func TestGroupModulePreconditions(t *testing.T) {
+	newVersionedAPI := func(version string, ifMatch *[]string) api.API {
+		return getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			*ifMatch = append(*ifMatch, request.Header.Get("If-Match"))
+			header := http.Header{"Etag": []string{version}}
+			if match := request.Header.Get("If-Match"); match != "" && match != version {
+				return &http.Response{StatusCode: http.StatusPreconditionFailed, Header: header, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+			} else if request.Header.Get("If-None-Match") == version {
+				return &http.Response{StatusCode: http.StatusNotModified, Header: header, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Header: header, Body: ioutil.NopCloser(strings.NewReader(`{"id": "xxx", "displayName": "yyy"}`))}, nil
+		})
+	}
+
+	t.Run("should only replace the group when it still has the found version", func(t *testing.T) {
+		var ifMatch []string
+		module := NewMockGroupModule(service.NewGroupService(newVersionedAPI(`W/"1"`, &ifMatch), api.NewStaticTokenSource("token")))
+		group, err := module.Find(context.Background(), "xxx")
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal(`W/"1"`, group.Meta.Version)
+		_, err = module.Replace(context.Background(), "xxx", models.ReplaceGroupBody{DisplayName: "zzz"}, &models.WriteOptions{IfMatch: group.Meta.Version})
+		assertT.Nil(err)
+		_, err = module.UpdateAddMembers(context.Background(), "xxx", []models.GroupMember{{ID: "www", Email: "www@example.com"}}, &models.WriteOptions{IfMatch: `W/"0"`})
+		assertT.True(errors.Is(err, api.ErrPreconditionFailed))
+		_, err = module.Delete(context.Background(), "xxx")
+		assertT.Nil(err)
+		assertT.Equal([]string{"", `W/"1"`, `W/"0"`, ""}, ifMatch)
+	})
+
+	t.Run("should return a not modified error when the group didn't change", func(t *testing.T) {
+		var ifMatch []string
+		module := NewMockGroupModule(service.NewGroupService(newVersionedAPI(`W/"1"`, &ifMatch), api.NewStaticTokenSource("token")))
+		group, err := module.FindWithOptions(context.Background(), "xxx", &models.FindOptions{IfNoneMatch: `W/"1"`})
+		assertT := assert.New(t)
+
+		assertT.Nil(group)
+		assertT.True(errors.Is(err, api.ErrNotModified))
+	})
+}
+
+func TestGroupModulePatch(t *testing.T) {
+	t.Run("should send every operation in one request and return the updated group", func(t *testing.T) {
+		var requests []string
+		var body map[string]interface{}
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			requests = append(requests, request.Method+" "+request.URL.Path)
+			json.NewDecoder(request.Body).Decode(&body)
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"id": "group-id", "displayName": "Engineering"}`))}, nil
+		})
+		module := NewMockGroupModule(service.NewGroupService(mockApi, api.NewStaticTokenSource("token")))
+		patch := models.NewGroupPatch().ReplaceDisplayName("Engineering").AddMembers(models.GroupMember{ID: "user-id"})
+		group, err := module.Patch(context.Background(), "group-id", patch)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("Engineering", group.DisplayName)
+		assertT.Equal([]string{"PATCH /provisioning/generic/v2/Groups/group-id"}, requests)
+		assertT.Len(body["Operations"], 2)
+	})
+
+	t.Run("should find the group when the server doesn't return it", func(t *testing.T) {
+		var requests []string
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			requests = append(requests, request.Method)
+			if request.Method == http.MethodPatch {
+				return &http.Response{StatusCode: http.StatusNoContent, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(`{"id": "group-id", "displayName": "Engineering"}`))}, nil
+		})
+		module := NewMockGroupModule(service.NewGroupService(mockApi, api.NewStaticTokenSource("token")))
+		group, err := module.Patch(context.Background(), "group-id", models.NewGroupPatch().RemoveMembersByID("user-id"))
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("group-id", group.ID)
+		assertT.Equal([]string{http.MethodPatch, http.MethodGet}, requests)
+	})
+}
+
+func TestGroupModuleOperation(t *testing.T) {
+	t.Run("should store the operation name and resource id in the request context", func(t *testing.T) {
+		var operation api.Operation
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			operation = api.OperationFromContext(request.Context())
+			return &http.Response{StatusCode: http.StatusNoContent}, nil
+		})
+		serviceApi := service.NewGroupService(mockApi, api.NewStaticTokenSource("token"))
+		module := NewMockGroupModule(serviceApi)
+		ok, err := module.UpdateRemoveMemberByID(context.Background(), "xxx", "yyy")
+		assertT := assert.New(t)
+
+		assertT.True(ok)
+		assertT.Nil(err)
+		assertT.Equal("Groups.UpdateRemoveMemberByID", operation.Name)
+		assertT.Equal("xxx", operation.ResourceID)
+	})
+}
+
+func TestGroupModuleTracing(t *testing.T) {
+	t.Run("should create a span for each module method", func(t *testing.T) {
+		exporter := tracetest.NewInMemoryExporter()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusNotFound, Body: ioutil.NopCloser(strings.NewReader(`{"detail": "not found"}`))}, nil
+		})
+		serviceApi := service.NewGroupService(mockApi, api.NewStaticTokenSource("token"))
+		module := NewGroupModule(serviceApi, "", Instrumentation{Tracer: api.NewTracer(provider)}, nil)
+		_, err := module.Find(context.Background(), "xxx")
+		spans := exporter.GetSpans()
+		assertT := assert.New(t)
+
+		assertT.NotNil(err)
+		assertT.Len(spans, 1)
+		assertT.Equal("Groups.Find", spans[0].Name)
+		assertT.Equal(trace.SpanKindInternal, spans[0].SpanKind)
+		assertT.Equal(codes.Error, spans[0].Status.Code)
+		assertT.Contains(spans[0].Attributes, attribute.String("scim.resource_type", "Groups"))
+		assertT.Contains(spans[0].Attributes, attribute.String("scim.resource_id", "xxx"))
+	})
+
+	t.Run("should create a span for each page fetched by the iterator", func(t *testing.T) {
+		exporter := tracetest.NewInMemoryExporter()
+		provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+		mockApi := getMockedAPI(mockedApiExecuteWithGroupPageResponse)
+		serviceApi := service.NewGroupService(mockApi, api.NewStaticTokenSource("token"))
+		module := NewGroupModule(serviceApi, "", Instrumentation{Tracer: api.NewTracer(provider)}, nil)
+		iterator := module.List(context.Background(), &models.PaginationOptions{PageSize: mockGroupsPageSize, Offset: 1, Filter: "displayName sw \"x\""})
+		for iterator.Next() {
+		}
+		spans := exporter.GetSpans()
+		assertT := assert.New(t)
+
+		assertT.Nil(iterator.Err())
+		assertT.Len(spans, 2)
+		assertT.Equal("Groups.List", spans[0].Name)
+		assertT.Contains(spans[0].Attributes, attribute.Int("scim.page.offset", 1))
+		assertT.Contains(spans[0].Attributes, attribute.Int("scim.page.size", mockGroupsPageSize))
+		assertT.Contains(spans[0].Attributes, attribute.String("scim.filter", "displayName sw \"[REDACTED]\""))
+		assertT.Contains(spans[1].Attributes, attribute.Int("scim.page.offset", 3))
+	})
+
+	t.Run("should only trace the attributes and operators of the filter", func(t *testing.T) {
+		expression := filter.Or(
+			filter.And(filter.Eq("userName", "jane@example.com"), filter.Pr("title")),
+			filter.Not(filter.Values("emails", filter.Co("value", "jane"))),
+			filter.Eq("manager", nil),
+		)
+		attrs := getPaginationAttributes(&models.PaginationOptions{FilterExpression: expression})
+
+		assert.Contains(t, attrs, attribute.String("scim.filter", `userName eq "[REDACTED]" and title pr or not (emails[value co "[REDACTED]"]) or manager eq null`))
+	})
+
+	t.Run("should trace the value path sub-attribute comparisons of a raw filter", func(t *testing.T) {
+		attrs := getPaginationAttributes(&models.PaginationOptions{Filter: `emails[type eq "work"].value eq "jane@example.com"`})
+
+		assert.Contains(t, attrs, attribute.String("scim.filter", `emails[type eq "[REDACTED]"].value eq "[REDACTED]"`))
+	})
+}
+
 func mockedApiExecuteWithGroupPageResponse(request *http.Request) (*http.Response, error) {
 	token := extractAuthorizationToken(request.Header.Get("Authorization"))
 	if token == "" {
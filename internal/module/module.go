@@ -1,10 +1,21 @@
 package module
 
 import (
+	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
 
+	"github.com/strongdm/scimsdk/filter"
+	"github.com/strongdm/scimsdk/internal/api"
+	"github.com/strongdm/scimsdk/internal/attributes"
 	"github.com/strongdm/scimsdk/internal/service"
 	"github.com/strongdm/scimsdk/models"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
 const (
@@ -13,6 +24,229 @@ const (
 	defaultUserSchema  = "urn:ietf:params:scim:schemas:core:2.0:User"
 )
 
+// redactedFilterValue replaces the compared values of the traced filters.
+const redactedFilterValue = "[REDACTED]"
+
+const (
+	usersOperationPrefix     = "Users"
+	groupsOperationPrefix    = "Groups"
+	discoveryOperationPrefix = "Discovery"
+	bulkOperationPrefix      = "Bulk"
+)
+
+// Instrumentation groups the observability hooks used by the modules.
+type Instrumentation struct {
+	Logger  *slog.Logger
+	Tracer  trace.Tracer
+	Metrics api.Metrics
+}
+
+// startOperation stores the operation in the context and starts its span.
+func (instrumentation Instrumentation) startOperation(ctx context.Context, prefix, method, resourceID string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	ctx = withOperation(ctx, prefix, method, resourceID)
+	tracer := instrumentation.Tracer
+	if tracer == nil {
+		tracer = api.NewTracer(nil)
+	}
+	attrs = append(attrs, attribute.String("scim.resource_type", prefix))
+	if resourceID != "" {
+		attrs = append(attrs, attribute.String("scim.resource_id", resourceID))
+	}
+	return tracer.Start(ctx, prefix+"."+method, trace.WithSpanKind(trace.SpanKindInternal), trace.WithAttributes(attrs...))
+}
+
+func endSpan(span trace.Span, err *error) {
+	if *err != nil {
+		message := api.RedactError(*err)
+		span.RecordError(errors.New(message))
+		span.SetStatus(codes.Error, message)
+	}
+	span.End()
+}
+
+func getPaginationAttributes(opts *models.PaginationOptions) []attribute.KeyValue {
+	if opts == nil {
+		return nil
+	}
+	attrs := []attribute.KeyValue{
+		attribute.Int("scim.page.offset", opts.Offset),
+		attribute.Int("scim.page.size", opts.PageSize),
+	}
+	if rawFilter, err := getFilter(opts); err == nil && rawFilter != "" {
+		if parsedFilter, err := filter.Parse(rawFilter); err == nil {
+			attrs = append(attrs, attribute.String("scim.filter", redactFilter(parsedFilter).String()))
+		}
+	}
+	return attrs
+}
+
+// redactFilter replaces the compared values of the filter, which often hold
+// PII like the emails, keeping the attribute paths and operators.
+func redactFilter(expression filter.Filter) filter.Filter {
+	switch typedExpression := expression.(type) {
+	case *filter.Comparison:
+		if typedExpression.Value == nil {
+			return typedExpression
+		}
+		return &filter.Comparison{Attribute: typedExpression.Attribute, Operator: typedExpression.Operator, Value: redactedFilterValue}
+	case *filter.Logical:
+		return &filter.Logical{Operator: typedExpression.Operator, Left: redactFilter(typedExpression.Left), Right: redactFilter(typedExpression.Right)}
+	case *filter.Negation:
+		return &filter.Negation{Filter: redactFilter(typedExpression.Filter)}
+	case *filter.ValuePath:
+		return &filter.ValuePath{Attribute: typedExpression.Attribute, Filter: redactFilter(typedExpression.Filter)}
+	case *filter.ValuePathComparison:
+		return &filter.ValuePathComparison{
+			ValuePath:  redactFilter(typedExpression.ValuePath).(*filter.ValuePath),
+			Comparison: redactFilter(typedExpression.Comparison).(*filter.Comparison),
+		}
+	}
+	return expression
+}
+
+// getFilter returns the raw filter, validated before sending it, or the
+// formatted filter expression of the pagination options.
+func getFilter(opts *models.PaginationOptions) (string, error) {
+	if opts.FilterExpression == nil {
+		if opts.Filter != "" {
+			if _, err := filter.Parse(opts.Filter); err != nil {
+				return "", err
+			}
+		}
+		return opts.Filter, nil
+	} else if opts.Filter != "" {
+		return "", errors.New("you must pass either the Filter or the FilterExpression")
+	}
+	return opts.FilterExpression.String(), nil
+}
+
+// findByExternalID returns the only resource of the iterator, which lists the
+// resources filtered by their externalId. It fails with an error matching
+// api.ErrNotFound when there's no resource, since the external ids are
+// expected to be unique.
+func findByExternalID[T interface{}](iterator models.Iterator[T], resourceName, externalID string) (*T, error) {
+	if !iterator.Next() {
+		if err := iterator.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s with external id %q: %w", resourceName, externalID, api.ErrNotFound)
+	}
+	resource := iterator.Value()
+	if iterator.Next() {
+		return nil, fmt.Errorf("more than one %s has the external id %q", resourceName, externalID)
+	} else if err := iterator.Err(); err != nil {
+		return nil, err
+	}
+	return resource, nil
+}
+
+// newExternalIDPaginationOptions lists at most two resources, which is
+// enough to know whether the external id is unique.
+func newExternalIDPaginationOptions(externalID string) *models.PaginationOptions {
+	return &models.PaginationOptions{
+		PageSize:         2,
+		FilterExpression: filter.Eq("externalId", externalID),
+	}
+}
+
+// withOperation stores the module operation in the context, so it's available
+// to the middlewares of every request it executes.
+func withOperation(ctx context.Context, prefix, method, resourceID string) context.Context {
+	return api.WithOperation(ctx, api.Operation{
+		Name:       prefix + "." + method,
+		ResourceID: resourceID,
+	})
+}
+
+func convertMetadataResponseToPorcelain(response *service.MetadataResponse) *models.Metadata {
+	if response == nil {
+		return nil
+	}
+	return &models.Metadata{
+		ResourceType: response.ResourceType,
+		Location:     response.Location,
+		Created:      response.Created,
+		LastModified: response.LastModified,
+		Version:      response.Version,
+	}
+}
+
+// convertExtensionsResponseToPorcelain decodes the schema extensions into
+// their registered types.
+func convertExtensionsResponseToPorcelain(response map[string]json.RawMessage) models.Extensions {
+	if response == nil {
+		return nil
+	}
+	extensions := models.Extensions{}
+	for schema, attributes := range response {
+		extension := models.NewExtension(schema)
+		if err := json.Unmarshal(attributes, extension); err != nil {
+			extension = &map[string]interface{}{}
+			json.Unmarshal(attributes, extension)
+		}
+		if attributesMap, ok := extension.(*map[string]interface{}); ok {
+			extensions[schema] = *attributesMap
+		} else {
+			extensions[schema] = extension
+		}
+	}
+	return extensions
+}
+
+// getSchemas returns the core schema followed by the schemas of the
+// extensions, sorted to keep the requests stable.
+func getSchemas(coreSchema string, extensions models.Extensions, extensionSchemas ...string) []string {
+	schemas := append([]string{coreSchema}, extensionSchemas...)
+	extensionsSchemas := []string{}
+	for schema := range extensions {
+		if !containsString(schemas, schema) {
+			extensionsSchemas = append(extensionsSchemas, schema)
+		}
+	}
+	sort.Strings(extensionsSchemas)
+	return append(schemas, extensionsSchemas...)
+}
+
+func containsString(values []string, value string) bool {
+	for _, item := range values {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
+
+// convertPorcelainToPatchRequest converts the patch operations, encoding
+// their porcelain values as SCIM attributes.
+func convertPorcelainToPatchRequest(operations []models.PatchOperation) (*service.PatchRequest, error) {
+	if len(operations) == 0 {
+		return nil, errors.New("you must pass the patch operations")
+	}
+	patchRequest := &service.PatchRequest{Schemas: []string{defaultPatchSchema}}
+	for _, operation := range operations {
+		operationRequest := &service.PatchOperationRequest{OP: string(operation.Op), Path: operation.Path}
+		switch operation.Op {
+		case models.PatchAdd, models.PatchReplace:
+			if operation.Value == nil {
+				return nil, fmt.Errorf("you must pass the value of the %s operation", operation.Op)
+			}
+			value, err := json.Marshal(attributes.Encode(operation.Value))
+			if err != nil {
+				return nil, err
+			}
+			operationRequest.Value = value
+		case models.PatchRemove:
+			if operation.Path == "" {
+				return nil, errors.New("you must pass the path of the remove operation")
+			}
+		default:
+			return nil, fmt.Errorf("invalid patch operation %q", operation.Op)
+		}
+		patchRequest.Operations = append(patchRequest.Operations, operationRequest)
+	}
+	return patchRequest, nil
+}
+
 func newServiceCreateOptions(body interface{}, url string) *service.CreateOptions {
 	return &service.CreateOptions{
 		Body:       body,
@@ -28,53 +262,97 @@ func newServiceListOptions(opts *models.PaginationOptions, url string) (*service
 		return nil, errors.New("the pagination offset must be positive")
 	} else if opts.PageSize < 0 {
 		return nil, errors.New("the pagination page size must be positive")
+	} else if opts.SortOrder != "" && opts.SortOrder != models.SortAscending && opts.SortOrder != models.SortDescending {
+		return nil, errors.New("the sort order must be ascending or descending")
+	}
+	if err := validateAttributes(opts.Attributes, opts.ExcludedAttributes); err != nil {
+		return nil, err
+	}
+	rawFilter, err := getFilter(opts)
+	if err != nil {
+		return nil, err
 	}
 	return &service.ListOptions{
-		PageSize:   opts.PageSize,
-		Offset:     opts.Offset,
-		Filter:     opts.Filter,
-		BaseAPIURL: url,
+		PageSize:           opts.PageSize,
+		Offset:             opts.Offset,
+		Filter:             rawFilter,
+		SortBy:             opts.SortBy,
+		SortOrder:          string(opts.SortOrder),
+		Attributes:         opts.Attributes,
+		ExcludedAttributes: opts.ExcludedAttributes,
+		Search:             opts.Search,
+		BaseAPIURL:         url,
 	}, nil
 }
 
-func newServiceFindOptions(id string, url string) (*service.FindOptions, error) {
+func newServiceFindOptions(id string, opts *models.FindOptions, url string) (*service.FindOptions, error) {
 	if id == "" {
 		return nil, errors.New("you must pass the resource id")
 	}
+	if opts == nil {
+		opts = &models.FindOptions{}
+	}
+	if err := validateAttributes(opts.Attributes, opts.ExcludedAttributes); err != nil {
+		return nil, err
+	}
 	return &service.FindOptions{
-		ID:         id,
-		BaseAPIURL: url,
+		ID:                 id,
+		Attributes:         opts.Attributes,
+		ExcludedAttributes: opts.ExcludedAttributes,
+		IfNoneMatch:        opts.IfNoneMatch,
+		BaseAPIURL:         url,
 	}, nil
 }
 
-func newServiceReplaceOptions(id string, body interface{}, url string) (*service.ReplaceOptions, error) {
+func validateAttributes(attributes, excludedAttributes []string) error {
+	if len(attributes) > 0 && len(excludedAttributes) > 0 {
+		return errors.New("you must pass either the Attributes or the ExcludedAttributes")
+	}
+	return nil
+}
+
+func newServiceReplaceOptions(id string, body interface{}, url string, writeOpts ...*models.WriteOptions) (*service.ReplaceOptions, error) {
 	if id == "" {
 		return nil, errors.New("you must pass the resource id")
 	}
 	return &service.ReplaceOptions{
 		ID:         id,
 		Body:       body,
+		IfMatch:    getIfMatch(writeOpts),
 		BaseAPIURL: url,
 	}, nil
 }
 
-func newServiceUpdateOptions(id string, body interface{}, url string) (*service.UpdateOptions, error) {
+func newServiceUpdateOptions(id string, body interface{}, url string, writeOpts ...*models.WriteOptions) (*service.UpdateOptions, error) {
 	if id == "" {
 		return nil, errors.New("you must pass the resource id")
 	}
 	return &service.UpdateOptions{
 		ID:         id,
 		Body:       body,
+		IfMatch:    getIfMatch(writeOpts),
 		BaseAPIURL: url,
 	}, nil
 }
 
-func newServiceDeleteOptions(id string, url string) (*service.DeleteOptions, error) {
+func newServiceDeleteOptions(id string, url string, writeOpts ...*models.WriteOptions) (*service.DeleteOptions, error) {
 	if id == "" {
 		return nil, errors.New("you must pass the resource id")
 	}
 	return &service.DeleteOptions{
 		ID:         id,
+		IfMatch:    getIfMatch(writeOpts),
 		BaseAPIURL: url,
 	}, nil
 }
+
+// getIfMatch returns the version of the last write options passed, or an
+// empty string to send the request unconditionally.
+func getIfMatch(writeOpts []*models.WriteOptions) string {
+	for i := len(writeOpts) - 1; i >= 0; i-- {
+		if writeOpts[i] != nil {
+			return writeOpts[i].IfMatch
+		}
+	}
+	return ""
+}
@@ -1,30 +1,39 @@
 package module
 
 import (
+	"context"
+
+	"github.com/strongdm/scimsdk/internal/api"
 	"github.com/strongdm/scimsdk/models"
 )
 
 type iteratorFetchFunc[T interface{}] func(opts *models.PaginationOptions) (data []*T, haveNextPage bool, err error)
 
 type iteratorImpl[T interface{}] struct {
-	buffer       []*T
-	index        int
-	haveNextPage bool
-	fetchFn      iteratorFetchFunc[T]
-	err          error
-	opts         *models.PaginationOptions
+	buffer          []*T
+	index           int
+	haveNextPage    bool
+	fetchFn         iteratorFetchFunc[T]
+	err             error
+	opts            *models.PaginationOptions
+	ctx             context.Context
+	instrumentation Instrumentation
 }
 
-func newIterator[T interface{}](fetchFn iteratorFetchFunc[T], opts *models.PaginationOptions) *iteratorImpl[T] {
+func newIterator[T interface{}](ctx context.Context, fetchFn iteratorFetchFunc[T], opts *models.PaginationOptions, instrumentation Instrumentation) *iteratorImpl[T] {
 	if opts == nil {
 		opts = &models.PaginationOptions{
 			Offset: 1,
 		}
+	} else if opts.Offset == 0 {
+		opts.Offset = 1
 	}
 	return &iteratorImpl[T]{
-		haveNextPage: true,
-		fetchFn:      fetchFn,
-		opts:         opts,
+		haveNextPage:    true,
+		fetchFn:         fetchFn,
+		opts:            opts,
+		ctx:             ctx,
+		instrumentation: instrumentation,
 	}
 }
 
@@ -39,6 +48,10 @@ func (it *iteratorImpl[T]) Next() bool {
 	it.opts.Offset = len(it.buffer) + it.opts.Offset
 	it.index = 0
 	it.buffer, it.haveNextPage, it.err = it.fetchFn(it.opts)
+	api.LogPageFetch(it.ctx, it.instrumentation.Logger, it.opts.Offset, it.opts.PageSize, len(it.buffer), it.haveNextPage, it.err)
+	if it.instrumentation.Metrics != nil {
+		it.instrumentation.Metrics.ObservePage(api.OperationFromContext(it.ctx).Name, len(it.buffer), it.err)
+	}
 	return len(it.buffer) > 0
 }
 
@@ -0,0 +1,236 @@
+package module
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/strongdm/scimsdk/internal/api"
+	"github.com/strongdm/scimsdk/internal/attributes"
+	"github.com/strongdm/scimsdk/internal/service"
+	"github.com/strongdm/scimsdk/models"
+)
+
+// defaultMaxSortedResources limits the resources fetched to sort a list on
+// the client.
+const defaultMaxSortedResources = 10000
+
+// serviceProviderConfigErrorTTL is how long a failed ServiceProviderConfig
+// request is cached, unless the server doesn't have the endpoint.
+const serviceProviderConfigErrorTTL = 10 * time.Second
+
+// ServiceProviderConfigLoader loads the server ServiceProviderConfig once and
+// shares it between the modules of a client.
+type ServiceProviderConfigLoader struct {
+	service            service.DiscoveryService
+	providedURL        string
+	maxSortedResources int
+	mu                 sync.Mutex
+	config             *service.ServiceProviderConfigResponse
+	err                error
+	errExpiration      time.Time
+}
+
+func NewServiceProviderConfigLoader(service service.DiscoveryService, providedURL string, maxSortedResources int) *ServiceProviderConfigLoader {
+	if maxSortedResources <= 0 {
+		maxSortedResources = defaultMaxSortedResources
+	}
+	return &ServiceProviderConfigLoader{service: service, providedURL: providedURL, maxSortedResources: maxSortedResources}
+}
+
+// Load returns the cached ServiceProviderConfig, requesting it when it
+// wasn't loaded yet. When the server doesn't have the endpoint, its error is
+// cached too, and the other errors are cached for a short time so the callers
+// don't pay a failing request each.
+func (loader *ServiceProviderConfigLoader) Load(ctx context.Context) (*service.ServiceProviderConfigResponse, error) {
+	loader.mu.Lock()
+	defer loader.mu.Unlock()
+	if loader.config != nil {
+		return loader.config, nil
+	}
+	if loader.err != nil && (loader.errExpiration.IsZero() || time.Now().Before(loader.errExpiration)) {
+		return nil, loader.err
+	}
+	config, err := loader.service.FindServiceProviderConfig(ctx, &service.FindOptions{BaseAPIURL: loader.providedURL})
+	if err != nil {
+		loader.cacheError(ctx, err)
+		return nil, err
+	}
+	loader.config = config
+	return config, nil
+}
+
+func (loader *ServiceProviderConfigLoader) cacheError(ctx context.Context, err error) {
+	if ctx.Err() != nil {
+		return
+	}
+	loader.err = err
+	loader.errExpiration = time.Time{}
+	if !isUnsupportedEndpoint(err) {
+		loader.errExpiration = time.Now().Add(serviceProviderConfigErrorTTL)
+	}
+}
+
+// isUnsupportedEndpoint reports whether the error is the response of a server
+// that doesn't implement the requested endpoint.
+func isUnsupportedEndpoint(err error) bool {
+	var responseErr *api.Error
+	if !errors.As(err, &responseErr) {
+		return false
+	}
+	return responseErr.StatusCode == http.StatusNotFound || responseErr.StatusCode == http.StatusNotImplemented
+}
+
+// isSortSupported reports whether the server sorts the resources. When the
+// ServiceProviderConfig can't be loaded, the sorting is left to the server.
+func (loader *ServiceProviderConfigLoader) isSortSupported(ctx context.Context) bool {
+	if loader == nil {
+		return true
+	}
+	config, err := loader.Load(ctx)
+	if err != nil {
+		return true
+	}
+	return config.Sort != nil && config.Sort.Supported
+}
+
+// withClientSideSort wraps the fetch function, so when the server doesn't
+// support sorting every page is fetched once, sorted in memory and served
+// from the sorted resources. It fails when there are more resources than the
+// max sorted resources of the loader.
+func withClientSideSort[T interface{}](ctx context.Context, loader *ServiceProviderConfigLoader, fetchFn iteratorFetchFunc[T]) iteratorFetchFunc[T] {
+	var pageFetchFn iteratorFetchFunc[T]
+	return func(opts *models.PaginationOptions) ([]*T, bool, error) {
+		if pageFetchFn != nil {
+			return pageFetchFn(opts)
+		}
+		if opts.SortBy == "" || loader.isSortSupported(ctx) {
+			pageFetchFn = fetchFn
+			return fetchFn(opts)
+		}
+		resources, err := fetchAllPages(fetchFn, opts, loader.maxSortedResources)
+		if err != nil {
+			return nil, false, err
+		}
+		sortResources(resources, opts.SortBy, opts.SortOrder)
+		pageFetchFn = func(opts *models.PaginationOptions) ([]*T, bool, error) {
+			return getSortedPage(resources, opts)
+		}
+		return pageFetchFn(opts)
+	}
+}
+
+func fetchAllPages[T interface{}](fetchFn iteratorFetchFunc[T], opts *models.PaginationOptions, maxResources int) ([]*T, error) {
+	pageOpts := *opts
+	pageOpts.Offset = 1
+	pageOpts.SortBy = ""
+	pageOpts.SortOrder = ""
+	resources := []*T{}
+	for {
+		page, haveNextPage, err := fetchFn(&pageOpts)
+		if err != nil {
+			return nil, err
+		}
+		resources = append(resources, page...)
+		if len(resources) > maxResources {
+			return nil, fmt.Errorf("the server doesn't support sorting and there are more than %d resources to sort on the client", maxResources)
+		}
+		if !haveNextPage || len(page) == 0 {
+			return resources, nil
+		}
+		pageOpts.Offset += len(page)
+	}
+}
+
+func getSortedPage[T interface{}](resources []*T, opts *models.PaginationOptions) ([]*T, bool, error) {
+	start := opts.Offset - 1
+	if start < 0 {
+		start = 0
+	} else if start > len(resources) {
+		start = len(resources)
+	}
+	end := len(resources)
+	if opts.PageSize > 0 && start+opts.PageSize < end {
+		end = start + opts.PageSize
+	}
+	return resources[start:end], end < len(resources), nil
+}
+
+// sortResources sorts the resources by the first value of the attribute, or
+// its primary one when it's multi-valued. The resources without the attribute
+// are sorted last in ascending order.
+func sortResources[T interface{}](resources []*T, sortBy string, sortOrder models.SortOrder) {
+	values := make(map[*T]reflect.Value, len(resources))
+	for _, resource := range resources {
+		values[resource] = getSortValue(reflect.ValueOf(resource), sortBy)
+	}
+	sort.SliceStable(resources, func(i, j int) bool {
+		result := compareSortValues(values[resources[i]], values[resources[j]])
+		if sortOrder == models.SortDescending {
+			return result > 0
+		}
+		return result < 0
+	})
+}
+
+func getSortValue(resource reflect.Value, sortBy string) reflect.Value {
+	values := attributes.Resolve(resource, sortBy)
+	for _, value := range values {
+		if primary := attributes.Indirect(attributes.Get(value, "primary")); primary.Kind() == reflect.Bool && primary.Bool() {
+			values = []reflect.Value{value}
+			break
+		}
+	}
+	for _, value := range values {
+		if attributes.IsComplex(value) {
+			value = attributes.Indirect(attributes.Get(value, "value"))
+		}
+		if value.IsValid() && attributes.IsPresent(value) {
+			return value
+		}
+	}
+	return reflect.Value{}
+}
+
+// compareSortValues compares two attribute values, ignoring the case of the
+// strings. The missing values are greater than any other.
+func compareSortValues(a, b reflect.Value) int {
+	if !a.IsValid() || !b.IsValid() {
+		return boolToInt(!a.IsValid()) - boolToInt(!b.IsValid())
+	}
+	switch {
+	case a.Kind() == reflect.String && b.Kind() == reflect.String:
+		return strings.Compare(strings.ToLower(a.String()), strings.ToLower(b.String()))
+	case a.Kind() == reflect.Bool && b.Kind() == reflect.Bool:
+		return boolToInt(a.Bool()) - boolToInt(b.Bool())
+	case a.Type() == attributes.TimeType && b.Type() == attributes.TimeType:
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+	case a.CanFloat() && b.CanFloat():
+		return compareFloats(a.Float(), b.Float())
+	case a.CanInt() && b.CanInt():
+		return compareFloats(float64(a.Int()), float64(b.Int()))
+	}
+	return 0
+}
+
+func compareFloats(a, b float64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+func boolToInt(value bool) int {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,183 @@
+package module
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strongdm/scimsdk/internal/api"
+	"github.com/strongdm/scimsdk/internal/service"
+	"github.com/strongdm/scimsdk/models"
+)
+
+var mockUnsortedUserNames = []string{"carol", "Alice", "eve", "", "bob"}
+
+func TestUsersListSorting(t *testing.T) {
+	t.Run("should sort the users on the client when the server doesn't support sorting", func(t *testing.T) {
+		var queries []string
+		mockApi := getMockedAPI(mockedApiExecuteWithUnsortedUsers(false, &queries))
+		module := NewUserModule(service.NewUserService(mockApi, api.NewStaticTokenSource("token")), "", Instrumentation{}, newMockServiceProviderConfigLoader(mockApi))
+		iterator := module.List(context.Background(), &models.PaginationOptions{PageSize: 2, SortBy: "userName"})
+		userNames := []string{}
+		for iterator.Next() {
+			userNames = append(userNames, iterator.Value().UserName)
+		}
+		assertT := assert.New(t)
+
+		assertT.Nil(iterator.Err())
+		assertT.Equal([]string{"Alice", "bob", "carol", "eve", ""}, userNames)
+		for _, query := range queries {
+			assertT.NotContains(query, "sortBy")
+		}
+	})
+
+	t.Run("should sort the users in descending order from the passed offset", func(t *testing.T) {
+		var queries []string
+		mockApi := getMockedAPI(mockedApiExecuteWithUnsortedUsers(false, &queries))
+		module := NewUserModule(service.NewUserService(mockApi, api.NewStaticTokenSource("token")), "", Instrumentation{}, newMockServiceProviderConfigLoader(mockApi))
+		iterator := module.List(context.Background(), &models.PaginationOptions{PageSize: 2, Offset: 3, SortBy: "userName", SortOrder: models.SortDescending})
+		userNames := []string{}
+		for iterator.Next() {
+			userNames = append(userNames, iterator.Value().UserName)
+		}
+
+		assert.Nil(t, iterator.Err())
+		assert.Equal(t, []string{"carol", "bob", "Alice"}, userNames)
+	})
+
+	t.Run("should return an error when there are more users than the max sorted resources", func(t *testing.T) {
+		var queries []string
+		mockApi := getMockedAPI(mockedApiExecuteWithUnsortedUsers(false, &queries))
+		loader := NewServiceProviderConfigLoader(service.NewDiscoveryService(mockApi, api.NewStaticTokenSource("token")), "", 3)
+		module := NewUserModule(service.NewUserService(mockApi, api.NewStaticTokenSource("token")), "", Instrumentation{}, loader)
+		iterator := module.List(context.Background(), &models.PaginationOptions{PageSize: 2, SortBy: "userName"})
+		assertT := assert.New(t)
+
+		assertT.False(iterator.Next())
+		assertT.EqualError(iterator.Err(), "the server doesn't support sorting and there are more than 3 resources to sort on the client")
+		assertT.Len(queries, 2)
+	})
+
+	t.Run("should send the sort params when the server supports sorting", func(t *testing.T) {
+		var queries []string
+		mockApi := getMockedAPI(mockedApiExecuteWithUnsortedUsers(true, &queries))
+		module := NewUserModule(service.NewUserService(mockApi, api.NewStaticTokenSource("token")), "", Instrumentation{}, newMockServiceProviderConfigLoader(mockApi))
+		iterator := module.List(context.Background(), &models.PaginationOptions{PageSize: 5, SortBy: "userName", SortOrder: models.SortDescending})
+		for iterator.Next() {
+		}
+		assertT := assert.New(t)
+
+		assertT.Nil(iterator.Err())
+		assertT.NotEmpty(queries)
+		assertT.Contains(queries[0], "sortBy=userName")
+		assertT.Contains(queries[0], "sortOrder=descending")
+	})
+
+	t.Run("should return an error when the sort order is invalid", func(t *testing.T) {
+		var queries []string
+		mockApi := getMockedAPI(mockedApiExecuteWithUnsortedUsers(true, &queries))
+		module := NewUserModule(service.NewUserService(mockApi, api.NewStaticTokenSource("token")), "", Instrumentation{}, nil)
+		iterator := module.List(context.Background(), &models.PaginationOptions{SortBy: "userName", SortOrder: "up"})
+		assertT := assert.New(t)
+
+		assertT.False(iterator.Next())
+		assertT.Contains(iterator.Err().Error(), "must be ascending or descending")
+		assertT.Empty(queries)
+	})
+}
+
+func TestSortResources(t *testing.T) {
+	t.Run("should sort by the primary value of a multi-valued attribute", func(t *testing.T) {
+		users := []*models.User{
+			{ID: "1", Emails: []models.UserEmail{{Value: "a@example.com"}, {Value: "z@example.com", Primary: true}}},
+			{ID: "2", Emails: []models.UserEmail{{Value: "m@example.com"}}},
+			{ID: "3"},
+		}
+		sortResources(users, "emails", models.SortAscending)
+
+		assert.Equal(t, "2", users[0].ID)
+		assert.Equal(t, "1", users[1].ID)
+		assert.Equal(t, "3", users[2].ID)
+	})
+}
+
+func TestServiceProviderConfigLoader(t *testing.T) {
+	t.Run("should cache the error when the server doesn't have the endpoint", func(t *testing.T) {
+		requests := 0
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			requests++
+			return &http.Response{StatusCode: http.StatusNotFound, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{}`))}, nil
+		})
+		loader := newMockServiceProviderConfigLoader(mockApi)
+		assertT := assert.New(t)
+
+		for i := 0; i < 3; i++ {
+			_, err := loader.Load(context.Background())
+			assertT.ErrorIs(err, api.ErrNotFound)
+		}
+		assertT.Equal(1, requests)
+		assertT.True(loader.errExpiration.IsZero())
+	})
+
+	t.Run("should cache the other errors for a short time", func(t *testing.T) {
+		requests := 0
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			requests++
+			return &http.Response{StatusCode: http.StatusServiceUnavailable, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(`{}`))}, nil
+		})
+		loader := newMockServiceProviderConfigLoader(mockApi)
+		assertT := assert.New(t)
+
+		_, err := loader.Load(context.Background())
+		assertT.ErrorIs(err, api.ErrServer)
+		_, err = loader.Load(context.Background())
+		assertT.ErrorIs(err, api.ErrServer)
+		assertT.Equal(1, requests)
+		loader.errExpiration = time.Now().Add(-time.Second)
+		_, err = loader.Load(context.Background())
+		assertT.ErrorIs(err, api.ErrServer)
+		assertT.Equal(2, requests)
+	})
+
+	t.Run("should not cache the error of a canceled context", func(t *testing.T) {
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			return nil, request.Context().Err()
+		})
+		loader := newMockServiceProviderConfigLoader(mockApi)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, err := loader.Load(ctx)
+		assertT := assert.New(t)
+
+		assertT.Error(err)
+		assertT.Nil(loader.err)
+	})
+}
+
+func newMockServiceProviderConfigLoader(mockApi api.API) *ServiceProviderConfigLoader {
+	return NewServiceProviderConfigLoader(service.NewDiscoveryService(mockApi, api.NewStaticTokenSource("token")), "", 0)
+}
+
+func mockedApiExecuteWithUnsortedUsers(sortSupported bool, queries *[]string) func(*http.Request) (*http.Response, error) {
+	return func(request *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(request.URL.Path, "/ServiceProviderConfig") {
+			body := fmt.Sprintf(`{"sort": {"supported": %t}}`, sortSupported)
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		}
+		*queries = append(*queries, request.URL.RawQuery)
+		startIndex, _ := strconv.Atoi(request.URL.Query().Get("startIndex"))
+		count, _ := strconv.Atoi(request.URL.Query().Get("count"))
+		resources := []string{}
+		for i := startIndex - 1; i >= 0 && i < len(mockUnsortedUserNames) && i < startIndex-1+count; i++ {
+			resources = append(resources, fmt.Sprintf(`{"id": "%d", "userName": "%s"}`, i, mockUnsortedUserNames[i]))
+		}
+		body := fmt.Sprintf(`{"Resources": [%s], "itemsPerPage": %d}`, strings.Join(resources, ","), count)
+		return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+	}
+}
@@ -12,9 +12,9 @@ func getMockedAPI(mockedFn func(*http.Request) (*http.Response, error)) api.API
 }
 
 func NewMockGroupModule(service service.GroupService) *groupModuleImpl {
-	return &groupModuleImpl{service, ""}
+	return &groupModuleImpl{service, "", Instrumentation{}, nil}
 }
 
 func NewMockUserModule(svc service.UserService) *userModuleImpl {
-	return &userModuleImpl{svc, ""}
+	return &userModuleImpl{svc, "", Instrumentation{}, nil}
 }
@@ -2,21 +2,26 @@ package module
 
 import (
 	"context"
+	"errors"
 
 	"github.com/strongdm/scimsdk/internal/service"
 	"github.com/strongdm/scimsdk/models"
 )
 
 type userModuleImpl struct {
-	service     service.UserService
-	providedURL string
+	service               service.UserService
+	providedURL           string
+	instrumentation       Instrumentation
+	serviceProviderConfig *ServiceProviderConfigLoader
 }
 
-func NewUserModule(service service.UserService, providedURL string) *userModuleImpl {
-	return &userModuleImpl{service, providedURL}
+func NewUserModule(service service.UserService, providedURL string, instrumentation Instrumentation, serviceProviderConfig *ServiceProviderConfigLoader) *userModuleImpl {
+	return &userModuleImpl{service, providedURL, instrumentation, serviceProviderConfig}
 }
 
-func (module *userModuleImpl) Create(ctx context.Context, user models.CreateUser) (*models.User, error) {
+func (module *userModuleImpl) Create(ctx context.Context, user models.CreateUser) (_ *models.User, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, usersOperationPrefix, "Create", "")
+	defer endSpan(span, &err)
 	body, err := convertPorcelainToCreateUserRequest(&user)
 	if err != nil {
 		return nil, err
@@ -30,11 +35,19 @@ func (module *userModuleImpl) Create(ctx context.Context, user models.CreateUser
 }
 
 func (module *userModuleImpl) List(ctx context.Context, paginationOpts *models.PaginationOptions) models.Iterator[models.User] {
-	return newIterator(module.iteratorMiddleware(ctx), paginationOpts)
+	ctx = withOperation(ctx, usersOperationPrefix, "List", "")
+	fetchFn := withClientSideSort(ctx, module.serviceProviderConfig, module.iteratorMiddleware(ctx))
+	return newIterator(ctx, fetchFn, paginationOpts, module.instrumentation)
 }
 
 func (module *userModuleImpl) Find(ctx context.Context, id string) (*models.User, error) {
-	opts, err := newServiceFindOptions(id, module.providedURL)
+	return module.FindWithOptions(ctx, id, nil)
+}
+
+func (module *userModuleImpl) FindWithOptions(ctx context.Context, id string, findOpts *models.FindOptions) (_ *models.User, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, usersOperationPrefix, "Find", id)
+	defer endSpan(span, &err)
+	opts, err := newServiceFindOptions(id, findOpts, module.providedURL)
 	if err != nil {
 		return nil, err
 	}
@@ -45,12 +58,23 @@ func (module *userModuleImpl) Find(ctx context.Context, id string) (*models.User
 	return convertUserResponseToPorcelain(response), nil
 }
 
-func (module *userModuleImpl) Replace(ctx context.Context, id string, user models.ReplaceUser) (*models.User, error) {
+func (module *userModuleImpl) FindByExternalID(ctx context.Context, externalID string) (_ *models.User, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, usersOperationPrefix, "FindByExternalID", "")
+	defer endSpan(span, &err)
+	if externalID == "" {
+		return nil, errors.New("you must pass the user external id")
+	}
+	return findByExternalID(module.List(ctx, newExternalIDPaginationOptions(externalID)), "user", externalID)
+}
+
+func (module *userModuleImpl) Replace(ctx context.Context, id string, user models.ReplaceUser, writeOpts ...*models.WriteOptions) (_ *models.User, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, usersOperationPrefix, "Replace", id)
+	defer endSpan(span, &err)
 	body, err := convertPorcelainToReplaceUserRequest(id, &user)
 	if err != nil {
 		return nil, err
 	}
-	opts, err := newServiceReplaceOptions(id, body, module.providedURL)
+	opts, err := newServiceReplaceOptions(id, body, module.providedURL, writeOpts...)
 	if err != nil {
 		return nil, err
 	}
@@ -61,17 +85,35 @@ func (module *userModuleImpl) Replace(ctx context.Context, id string, user model
 	return convertUserResponseToPorcelain(response), nil
 }
 
-func (module *userModuleImpl) Update(ctx context.Context, id string, updateUser models.UpdateUser) (bool, error) {
+func (module *userModuleImpl) Update(ctx context.Context, id string, updateUser models.UpdateUser, writeOpts ...*models.WriteOptions) (_ bool, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, usersOperationPrefix, "Update", id)
+	defer endSpan(span, &err)
 	body := convertPorcelainToUpdateUserRequest(updateUser)
-	opts, err := newServiceUpdateOptions(id, body, module.providedURL)
+	opts, err := newServiceUpdateOptions(id, body, module.providedURL, writeOpts...)
+	if err != nil {
+		return false, err
+	}
+	return module.service.Update(ctx, opts)
+}
+
+func (module *userModuleImpl) Patch(ctx context.Context, id string, patch *models.UserPatch, writeOpts ...*models.WriteOptions) (_ bool, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, usersOperationPrefix, "Patch", id)
+	defer endSpan(span, &err)
+	body, err := convertPorcelainToPatchUserRequest(patch)
+	if err != nil {
+		return false, err
+	}
+	opts, err := newServiceUpdateOptions(id, body, module.providedURL, writeOpts...)
 	if err != nil {
 		return false, err
 	}
 	return module.service.Update(ctx, opts)
 }
 
-func (module *userModuleImpl) Delete(ctx context.Context, id string) (bool, error) {
-	opts, err := newServiceDeleteOptions(id, module.providedURL)
+func (module *userModuleImpl) Delete(ctx context.Context, id string, writeOpts ...*models.WriteOptions) (_ bool, err error) {
+	ctx, span := module.instrumentation.startOperation(ctx, usersOperationPrefix, "Delete", id)
+	defer endSpan(span, &err)
+	opts, err := newServiceDeleteOptions(id, module.providedURL, writeOpts...)
 	if err != nil {
 		return false, err
 	}
@@ -79,7 +121,9 @@ func (module *userModuleImpl) Delete(ctx context.Context, id string) (bool, erro
 }
 
 func (module *userModuleImpl) iteratorMiddleware(ctx context.Context) iteratorFetchFunc[models.User] {
-	return func(opts *models.PaginationOptions) ([]*models.User, bool, error) {
+	return func(opts *models.PaginationOptions) (_ []*models.User, _ bool, err error) {
+		ctx, span := module.instrumentation.startOperation(ctx, usersOperationPrefix, "List", "", getPaginationAttributes(opts)...)
+		defer endSpan(span, &err)
 		listOpts, err := newServiceListOptions(opts, module.providedURL)
 		if err != nil {
 			return nil, false, err
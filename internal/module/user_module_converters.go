@@ -2,6 +2,7 @@ package module
 
 import (
 	"errors"
+	"fmt"
 
 	"github.com/strongdm/scimsdk/internal/service"
 	"github.com/strongdm/scimsdk/models"
@@ -17,22 +18,66 @@ func convertUserResponseListToPorcelain(response []*service.UserResponse) []*mod
 
 func convertUserResponseToPorcelain(response *service.UserResponse) *models.User {
 	return &models.User{
-		ID:          response.ID,
-		Active:      response.Active,
-		DisplayName: response.DisplayName,
-		Emails:      convertUserEmailResponseListToPorcelain(response.Emails),
-		Groups:      convertUserGroupReferenceResponseListToPorcelain(response.Groups),
-		Name:        convertUserNameResponseToPorcelain(response.Name),
-		UserName:    response.UserName,
-		UserType:    response.UserType,
+		ID:                response.ID,
+		ExternalID:        response.ExternalID,
+		Active:            response.Active,
+		DisplayName:       response.DisplayName,
+		Emails:            convertUserEmailResponseListToPorcelain(response.Emails),
+		Groups:            convertUserGroupReferenceResponseListToPorcelain(response.Groups),
+		Name:              convertUserNameResponseToPorcelain(response.Name),
+		UserName:          response.UserName,
+		UserType:          response.UserType,
+		Meta:              convertMetadataResponseToPorcelain(response.Meta),
+		NickName:          response.NickName,
+		ProfileURL:        response.ProfileURL,
+		Title:             response.Title,
+		PreferredLanguage: response.PreferredLanguage,
+		Locale:            response.Locale,
+		Timezone:          response.Timezone,
+		PhoneNumbers:      convertUserPhoneNumberResponseListToPorcelain(response.PhoneNumbers),
+		IMs:               convertUserAttributeResponseListToPorcelain(response.IMs),
+		Photos:            convertUserAttributeResponseListToPorcelain(response.Photos),
+		Addresses:         convertUserAddressResponseListToPorcelain(response.Addresses),
+		Entitlements:      convertUserAttributeResponseListToPorcelain(response.Entitlements),
+		Roles:             convertUserAttributeResponseListToPorcelain(response.Roles),
+		X509Certificates:  convertUserAttributeResponseListToPorcelain(response.X509Certificates),
+		EnterpriseUser:    convertEnterpriseUserResponseToPorcelain(response.EnterpriseUser),
+		Extensions:        convertExtensionsResponseToPorcelain(response.Extensions),
 	}
 }
 
-func convertUserNameResponseToPorcelain(response service.UserNameResponse) *models.UserName {
+func convertEnterpriseUserResponseToPorcelain(response *service.EnterpriseUserResponse) *models.EnterpriseUser {
+	if response == nil {
+		return nil
+	}
+	enterpriseUser := &models.EnterpriseUser{
+		EmployeeNumber: response.EmployeeNumber,
+		CostCenter:     response.CostCenter,
+		Organization:   response.Organization,
+		Division:       response.Division,
+		Department:     response.Department,
+	}
+	if response.Manager != nil {
+		enterpriseUser.Manager = &models.EnterpriseUserManager{
+			ID:          response.Manager.Value,
+			Ref:         response.Manager.Ref,
+			DisplayName: response.Manager.DisplayName,
+		}
+	}
+	return enterpriseUser
+}
+
+func convertUserNameResponseToPorcelain(response *service.UserNameResponse) *models.UserName {
+	if response == nil {
+		return &models.UserName{}
+	}
 	return &models.UserName{
-		Formatted:  response.Formatted,
-		FamilyName: response.FamilyName,
-		GivenName:  response.GivenName,
+		Formatted:       response.Formatted,
+		FamilyName:      response.FamilyName,
+		GivenName:       response.GivenName,
+		MiddleName:      response.MiddleName,
+		HonorificPrefix: response.HonorificPrefix,
+		HonorificSuffix: response.HonorificSuffix,
 	}
 }
 
@@ -44,6 +89,9 @@ func convertUserGroupReferenceResponseToPorcelain(response service.UserGroupRefe
 }
 
 func convertUserGroupReferenceResponseListToPorcelain(responses []service.UserGroupReferenceResponse) []models.UserGroupReference {
+	if responses == nil {
+		return nil
+	}
 	groups := []models.UserGroupReference{}
 	for _, response := range responses {
 		groups = append(groups, *convertUserGroupReferenceResponseToPorcelain(response))
@@ -51,21 +99,59 @@ func convertUserGroupReferenceResponseListToPorcelain(responses []service.UserGr
 	return groups
 }
 
-func convertUserEmailResponseListToPorcelain(response []service.UserEmailResponse) []models.UserEmail {
+func convertUserEmailResponseListToPorcelain(response []service.UserAttributeResponse) []models.UserEmail {
+	if response == nil {
+		return nil
+	}
 	emails := []models.UserEmail{}
 	for _, userEmail := range response {
-		emails = append(emails, convertUserEmailResponseToPorcelain(&userEmail))
+		emails = append(emails, models.UserEmail(convertUserAttributeResponseToPorcelain(&userEmail)))
 	}
 	return emails
 }
 
-func convertUserEmailResponseToPorcelain(response *service.UserEmailResponse) models.UserEmail {
-	return models.UserEmail{
+func convertUserPhoneNumberResponseListToPorcelain(response []service.UserAttributeResponse) []models.UserPhoneNumber {
+	if response == nil {
+		return nil
+	}
+	phoneNumbers := []models.UserPhoneNumber{}
+	for _, phoneNumber := range response {
+		phoneNumbers = append(phoneNumbers, models.UserPhoneNumber(convertUserAttributeResponseToPorcelain(&phoneNumber)))
+	}
+	return phoneNumbers
+}
+
+func convertUserAttributeResponseListToPorcelain(response []service.UserAttributeResponse) []models.UserAttribute {
+	if response == nil {
+		return nil
+	}
+	attributes := []models.UserAttribute{}
+	for _, attribute := range response {
+		attributes = append(attributes, convertUserAttributeResponseToPorcelain(&attribute))
+	}
+	return attributes
+}
+
+func convertUserAttributeResponseToPorcelain(response *service.UserAttributeResponse) models.UserAttribute {
+	return models.UserAttribute{
 		Primary: response.Primary,
 		Value:   response.Value,
+		Type:    response.Type,
+		Display: response.Display,
 	}
 }
 
+func convertUserAddressResponseListToPorcelain(response []service.UserAddressResponse) []models.UserAddress {
+	if response == nil {
+		return nil
+	}
+	addresses := []models.UserAddress{}
+	for _, address := range response {
+		addresses = append(addresses, models.UserAddress(address))
+	}
+	return addresses
+}
+
 func convertPorcelainToCreateUserRequest(user *models.CreateUser) (*service.CreateUserRequest, error) {
 	if user.UserName == "" {
 		return nil, errors.New("you must pass the user email in UserName field")
@@ -74,11 +160,19 @@ func convertPorcelainToCreateUserRequest(user *models.CreateUser) (*service.Crea
 	} else if user.FamilyName == "" {
 		return nil, errors.New("you must pass the user last name in FamilyName field")
 	}
+	attributes, err := convertPorcelainToUserAttributesRequest(user)
+	if err != nil {
+		return nil, err
+	}
 	return &service.CreateUserRequest{
-		Schemas:  []string{defaultUserSchema},
-		UserName: user.UserName,
-		Name:     service.UserNameRequest{GivenName: user.GivenName, FamilyName: user.FamilyName},
-		Active:   user.Active,
+		Schemas:               getUserSchemas(user.EnterpriseUser, user.Extensions),
+		ExternalID:            user.ExternalID,
+		UserName:              user.UserName,
+		Name:                  convertPorcelainToUserNameRequest(user),
+		Active:                user.Active,
+		UserAttributesRequest: *attributes,
+		EnterpriseUser:        convertPorcelainToEnterpriseUserRequest(user.EnterpriseUser),
+		Extensions:            user.Extensions,
 	}, nil
 }
 
@@ -92,15 +186,161 @@ func convertPorcelainToReplaceUserRequest(id string, user *models.ReplaceUser) (
 	} else if user.FamilyName == "" {
 		return nil, errors.New("you must pass the user last name in FamilyName field")
 	}
+	attributes, err := convertPorcelainToUserAttributesRequest((*models.CreateUser)(user))
+	if err != nil {
+		return nil, err
+	}
 	return &service.ReplaceUserRequest{
-		ID:       id,
-		Schemas:  []string{defaultUserSchema},
-		UserName: user.UserName,
-		Name:     service.UserNameRequest{GivenName: user.GivenName, FamilyName: user.FamilyName},
-		Active:   user.Active,
+		ID:                    id,
+		Schemas:               getUserSchemas(user.EnterpriseUser, user.Extensions),
+		ExternalID:            user.ExternalID,
+		UserName:              user.UserName,
+		Name:                  convertPorcelainToUserNameRequest((*models.CreateUser)(user)),
+		Active:                user.Active,
+		UserAttributesRequest: *attributes,
+		EnterpriseUser:        convertPorcelainToEnterpriseUserRequest(user.EnterpriseUser),
+		Extensions:            user.Extensions,
 	}, nil
 }
 
+func convertPorcelainToUserNameRequest(user *models.CreateUser) service.UserNameRequest {
+	return service.UserNameRequest{
+		GivenName:       user.GivenName,
+		FamilyName:      user.FamilyName,
+		MiddleName:      user.MiddleName,
+		HonorificPrefix: user.HonorificPrefix,
+		HonorificSuffix: user.HonorificSuffix,
+		Formatted:       user.FormattedName,
+	}
+}
+
+func convertPorcelainToUserAttributesRequest(user *models.CreateUser) (*service.UserAttributesRequest, error) {
+	emails := []models.UserAttribute{}
+	for _, email := range user.Emails {
+		emails = append(emails, models.UserAttribute(email))
+	}
+	phoneNumbers := []models.UserAttribute{}
+	for _, phoneNumber := range user.PhoneNumbers {
+		phoneNumbers = append(phoneNumbers, models.UserAttribute(phoneNumber))
+	}
+	request := &service.UserAttributesRequest{
+		DisplayName:       user.DisplayName,
+		NickName:          user.NickName,
+		ProfileURL:        user.ProfileURL,
+		Title:             user.Title,
+		UserType:          user.UserType,
+		PreferredLanguage: user.PreferredLanguage,
+		Locale:            user.Locale,
+		Timezone:          user.Timezone,
+		Password:          user.Password,
+	}
+	multiValuedAttributes := []struct {
+		name       string
+		attributes []models.UserAttribute
+		request    *[]service.UserAttributeRequest
+	}{
+		{"emails", emails, &request.Emails},
+		{"phoneNumbers", phoneNumbers, &request.PhoneNumbers},
+		{"ims", user.IMs, &request.IMs},
+		{"photos", user.Photos, &request.Photos},
+		{"entitlements", user.Entitlements, &request.Entitlements},
+		{"roles", user.Roles, &request.Roles},
+		{"x509Certificates", user.X509Certificates, &request.X509Certificates},
+	}
+	for _, attribute := range multiValuedAttributes {
+		values, err := convertPorcelainToUserAttributeRequestList(attribute.name, attribute.attributes)
+		if err != nil {
+			return nil, err
+		}
+		*attribute.request = values
+	}
+	addresses, err := convertPorcelainToUserAddressRequestList(user.Addresses)
+	if err != nil {
+		return nil, err
+	}
+	request.Addresses = addresses
+	return request, nil
+}
+
+func convertPorcelainToUserAttributeRequestList(name string, attributes []models.UserAttribute) ([]service.UserAttributeRequest, error) {
+	if len(attributes) == 0 {
+		return nil, nil
+	}
+	requests := []service.UserAttributeRequest{}
+	primaries := 0
+	for _, attribute := range attributes {
+		if attribute.Value == "" {
+			return nil, fmt.Errorf("you must pass the value of the %s", name)
+		}
+		if attribute.Primary {
+			primaries++
+		}
+		requests = append(requests, service.UserAttributeRequest{
+			Value:   attribute.Value,
+			Type:    attribute.Type,
+			Display: attribute.Display,
+			Primary: attribute.Primary,
+		})
+	}
+	if primaries > 1 {
+		return nil, fmt.Errorf("you must pass at most one primary value in %s", name)
+	}
+	return requests, nil
+}
+
+func convertPorcelainToUserAddressRequestList(addresses []models.UserAddress) ([]service.UserAddressRequest, error) {
+	if len(addresses) == 0 {
+		return nil, nil
+	}
+	requests := []service.UserAddressRequest{}
+	primaries := 0
+	for _, address := range addresses {
+		if address.Primary {
+			primaries++
+		}
+		requests = append(requests, service.UserAddressRequest{
+			Formatted:     address.Formatted,
+			StreetAddress: address.StreetAddress,
+			Locality:      address.Locality,
+			Region:        address.Region,
+			PostalCode:    address.PostalCode,
+			Country:       address.Country,
+			Type:          address.Type,
+			Primary:       address.Primary,
+		})
+	}
+	if primaries > 1 {
+		return nil, errors.New("you must pass at most one primary value in addresses")
+	}
+	return requests, nil
+}
+
+// getUserSchemas returns the core schema and the schemas of the extensions
+// sent in the request.
+func getUserSchemas(enterpriseUser *models.EnterpriseUser, extensions models.Extensions) []string {
+	if enterpriseUser != nil {
+		return getSchemas(defaultUserSchema, extensions, models.EnterpriseUserSchema)
+	}
+	return getSchemas(defaultUserSchema, extensions)
+}
+
+func convertPorcelainToEnterpriseUserRequest(enterpriseUser *models.EnterpriseUser) *service.EnterpriseUserRequest {
+	if enterpriseUser == nil {
+		return nil
+	}
+	request := &service.EnterpriseUserRequest{
+		EmployeeNumber: enterpriseUser.EmployeeNumber,
+		CostCenter:     enterpriseUser.CostCenter,
+		Organization:   enterpriseUser.Organization,
+		Division:       enterpriseUser.Division,
+		Department:     enterpriseUser.Department,
+	}
+	if enterpriseUser.Manager != nil && enterpriseUser.Manager.ID != "" {
+		request.Manager = &service.EnterpriseUserManagerRequest{Value: enterpriseUser.Manager.ID}
+	}
+	return request
+}
+
 func convertPorcelainToUpdateUserRequest(body models.UpdateUser) *service.UpdateUserRequest {
 	return &service.UpdateUserRequest{
 		Schemas: []string{defaultPatchSchema},
@@ -112,3 +352,10 @@ func convertPorcelainToUpdateUserRequest(body models.UpdateUser) *service.Update
 		},
 	}
 }
+
+func convertPorcelainToPatchUserRequest(patch *models.UserPatch) (*service.PatchRequest, error) {
+	if patch == nil {
+		return nil, errors.New("you must pass the user patch")
+	}
+	return convertPorcelainToPatchRequest(patch.Operations)
+}
@@ -1,15 +1,30 @@
 package module
 
 import (
+	"encoding/json"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/strongdm/scimsdk/filter"
+	"github.com/strongdm/scimsdk/internal/service"
 	"github.com/strongdm/scimsdk/models"
 )
 
 const mockUserID = "xxx"
 
 func TestConvertUserToAndFromPorcelain(t *testing.T) {
+	t.Run("should convert a partial user response without name, emails and groups", func(t *testing.T) {
+		user := convertUserResponseToPorcelain(&service.UserResponse{ID: "xxx", UserName: "yyy"})
+		assertT := assert.New(t)
+
+		assertT.Equal("xxx", user.ID)
+		assertT.Equal("yyy", user.UserName)
+		assertT.Equal(&models.UserName{}, user.Name)
+		assertT.Nil(user.Emails)
+		assertT.Nil(user.Groups)
+	})
+
 	t.Run("should convert a replace user body to api body when passing a valid replace user body", func(t *testing.T) {
 		body := getValidReplaceUser()
 		apiBody, err := convertPorcelainToReplaceUserRequest(mockUserID, body)
@@ -122,3 +137,241 @@ func getValidReplaceUser() *models.ReplaceUser {
 		Active:     true,
 	}
 }
+
+func TestConvertPorcelainToPatchUserRequest(t *testing.T) {
+	t.Run("should encode every operation of the patch", func(t *testing.T) {
+		patch := models.NewUserPatch().
+			Replace("name.givenName", "Jane").
+			Add("emails", []models.UserEmail{{Value: "jane@example.com"}}).
+			Replace("", map[string]interface{}{"active": false}).
+			Remove(filter.Values("emails", filter.Eq("value", "old@example.com")).String())
+		request, err := convertPorcelainToPatchUserRequest(patch)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal([]string{defaultPatchSchema}, request.Schemas)
+		assertT.Len(request.Operations, 4)
+		assertT.Equal("replace", request.Operations[0].OP)
+		assertT.JSONEq(`"Jane"`, string(request.Operations[0].Value))
+		assertT.JSONEq(`[{"primary": false, "value": "jane@example.com"}]`, string(request.Operations[1].Value))
+		assertT.Empty(request.Operations[2].Path)
+		assertT.JSONEq(`{"active": false}`, string(request.Operations[2].Value))
+		assertT.Equal("remove", request.Operations[3].OP)
+		assertT.Equal(`emails[value eq "old@example.com"]`, request.Operations[3].Path)
+		assertT.Nil(request.Operations[3].Value)
+	})
+
+	t.Run("should encode the porcelain models with their scim attribute names", func(t *testing.T) {
+		patch := models.NewUserPatch().Replace("name", &models.UserName{GivenName: "Jane", FamilyName: "Doe"})
+		request, err := convertPorcelainToPatchUserRequest(patch)
+
+		assert.Nil(t, err)
+		assert.JSONEq(t, `{"givenName": "Jane", "familyName": "Doe"}`, string(request.Operations[0].Value))
+	})
+
+	t.Run("should return an error when the patch is invalid", func(t *testing.T) {
+		assertT := assert.New(t)
+
+		_, err := convertPorcelainToPatchUserRequest(nil)
+		assertT.EqualError(err, "you must pass the user patch")
+		_, err = convertPorcelainToPatchUserRequest(models.NewUserPatch())
+		assertT.EqualError(err, "you must pass the patch operations")
+		_, err = convertPorcelainToPatchUserRequest(models.NewUserPatch().Remove(""))
+		assertT.EqualError(err, "you must pass the path of the remove operation")
+		_, err = convertPorcelainToPatchUserRequest(models.NewUserPatch().Add("nickName", nil))
+		assertT.EqualError(err, "you must pass the value of the add operation")
+		_, err = convertPorcelainToPatchUserRequest(&models.UserPatch{Operations: []models.PatchOperation{{Op: "move", Path: "nickName"}}})
+		assertT.EqualError(err, `invalid patch operation "move"`)
+	})
+}
+
+func TestConvertEnterpriseUserToAndFromPorcelain(t *testing.T) {
+	t.Run("should convert the enterprise user extension of a user response", func(t *testing.T) {
+		response := &service.UserResponse{
+			ID: "user-id",
+			EnterpriseUser: &service.EnterpriseUserResponse{
+				Department: "Engineering",
+				Manager:    &service.EnterpriseUserManagerResponse{Value: "manager-id", DisplayName: "Jane Doe"},
+			},
+		}
+		user := convertUserResponseToPorcelain(response)
+		assertT := assert.New(t)
+
+		assertT.Equal("Engineering", user.EnterpriseUser.Department)
+		assertT.Equal("manager-id", user.EnterpriseUser.Manager.ID)
+		assertT.Equal("Jane Doe", user.EnterpriseUser.Manager.DisplayName)
+		assertT.Nil(convertUserResponseToPorcelain(&service.UserResponse{}).EnterpriseUser)
+	})
+
+	t.Run("should send the enterprise user extension and its schema", func(t *testing.T) {
+		user := &models.CreateUser{
+			UserName:   "user@example.com",
+			GivenName:  "User",
+			FamilyName: "Name",
+			EnterpriseUser: &models.EnterpriseUser{
+				EmployeeNumber: "42",
+				Manager:        &models.EnterpriseUserManager{ID: "manager-id"},
+			},
+		}
+		request, err := convertPorcelainToCreateUserRequest(user)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal([]string{defaultUserSchema, models.EnterpriseUserSchema}, request.Schemas)
+		body, _ := json.Marshal(request)
+		assertT.Contains(string(body), `"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User":{"employeeNumber":"42","manager":{"value":"manager-id"}}`)
+	})
+
+	t.Run("should only send the core schema without the enterprise user extension", func(t *testing.T) {
+		replaceUser := &models.ReplaceUser{UserName: "user@example.com", GivenName: "User", FamilyName: "Name"}
+		request, err := convertPorcelainToReplaceUserRequest("user-id", replaceUser)
+
+		assert.Nil(t, err)
+		assert.Equal(t, []string{defaultUserSchema}, request.Schemas)
+		assert.Nil(t, request.EnterpriseUser)
+	})
+}
+
+type mockBadgeExtension struct {
+	BadgeID string `json:"badgeId"`
+}
+
+func TestConvertUserExtensionsToAndFromPorcelain(t *testing.T) {
+	models.RegisterExtension("urn:example:badge", mockBadgeExtension{})
+
+	t.Run("should decode the registered extensions into their types", func(t *testing.T) {
+		response := &service.UserResponse{
+			Extensions: map[string]json.RawMessage{
+				"urn:example:badge": json.RawMessage(`{"badgeId": "42"}`),
+				"urn:example:other": json.RawMessage(`{"level": 3}`),
+			},
+		}
+		user := convertUserResponseToPorcelain(response)
+		assertT := assert.New(t)
+
+		assertT.Equal(&mockBadgeExtension{BadgeID: "42"}, user.Extensions["urn:example:badge"])
+		assertT.Equal(map[string]interface{}{"level": float64(3)}, user.Extensions["urn:example:other"])
+		assertT.True(filter.Match(filter.Eq("urn:example:badge:badgeId", "42"), user))
+		assertT.True(filter.Match(filter.Gt("urn:example:other:level", 2), user))
+	})
+
+	t.Run("should send the extensions with their schemas", func(t *testing.T) {
+		user := &models.CreateUser{
+			UserName:       "user@example.com",
+			GivenName:      "User",
+			FamilyName:     "Name",
+			EnterpriseUser: &models.EnterpriseUser{Department: "Engineering"},
+			Extensions: models.Extensions{
+				"urn:example:other": map[string]interface{}{"level": 3},
+				"urn:example:badge": &mockBadgeExtension{BadgeID: "42"},
+			},
+		}
+		request, err := convertPorcelainToCreateUserRequest(user)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal([]string{defaultUserSchema, models.EnterpriseUserSchema, "urn:example:badge", "urn:example:other"}, request.Schemas)
+		body, _ := json.Marshal(request)
+		assertT.Contains(string(body), `"urn:example:badge":{"badgeId":"42"}`)
+		assertT.Contains(string(body), `"urn:example:other":{"level":3}`)
+	})
+}
+
+func TestConvertUserCoreAttributesToAndFromPorcelain(t *testing.T) {
+	t.Run("should send the optional core attributes", func(t *testing.T) {
+		body := getValidCreateUser()
+		body.ExternalID = "hr-123"
+		body.MiddleName = "Jane"
+		body.DisplayName = "Babs Jensen"
+		body.Title = "Tour Guide"
+		body.Locale = "en-US"
+		body.Timezone = "America/Los_Angeles"
+		body.Emails = []models.UserEmail{{Value: "bjensen@example.com", Type: "work", Primary: true}, {Value: "babs@jensen.org", Type: "home"}}
+		body.PhoneNumbers = []models.UserPhoneNumber{{Value: "555-555-8377", Type: "work"}}
+		body.Addresses = []models.UserAddress{{Locality: "Hollywood", Country: "USA", Type: "work", Primary: true}}
+		body.Roles = []models.UserAttribute{{Value: "admin"}}
+		request, err := convertPorcelainToCreateUserRequest(body)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("hr-123", request.ExternalID)
+		assertT.Equal("Jane", request.Name.MiddleName)
+		assertT.Equal([]service.UserAttributeRequest{{Value: "bjensen@example.com", Type: "work", Primary: true}, {Value: "babs@jensen.org", Type: "home"}}, request.Emails)
+		assertT.Equal([]service.UserAttributeRequest{{Value: "555-555-8377", Type: "work"}}, request.PhoneNumbers)
+		assertT.Equal([]service.UserAddressRequest{{Locality: "Hollywood", Country: "USA", Type: "work", Primary: true}}, request.Addresses)
+		jsonBody, _ := json.Marshal(request)
+		assertT.Contains(string(jsonBody), `"displayName":"Babs Jensen"`)
+		assertT.Contains(string(jsonBody), `"timezone":"America/Los_Angeles"`)
+		assertT.Contains(string(jsonBody), `"roles":[{"value":"admin"}]`)
+		assertT.NotContains(string(jsonBody), "ims")
+	})
+
+	t.Run("should return an error when passing more than one primary value", func(t *testing.T) {
+		body := getValidReplaceUser()
+		body.PhoneNumbers = []models.UserPhoneNumber{{Value: "555-555-8377", Primary: true}, {Value: "555-555-4823", Primary: true}}
+		_, err := convertPorcelainToReplaceUserRequest(mockUserID, body)
+
+		assert.EqualError(t, err, "you must pass at most one primary value in phoneNumbers")
+	})
+
+	t.Run("should return an error when passing an empty multi-valued attribute value", func(t *testing.T) {
+		body := getValidCreateUser()
+		body.Emails = []models.UserEmail{{Type: "work"}}
+		_, err := convertPorcelainToCreateUserRequest(body)
+
+		assert.EqualError(t, err, "you must pass the value of the emails")
+	})
+
+	t.Run("should convert the core attributes of a user response", func(t *testing.T) {
+		response := &service.UserResponse{}
+		err := json.Unmarshal([]byte(`{
+			"id": "xxx",
+			"externalId": "hr-123",
+			"nickName": "Babs",
+			"name": {"givenName": "Barbara", "honorificPrefix": "Ms."},
+			"emails": [{"value": "bjensen@example.com", "type": "work", "primary": true}],
+			"phoneNumbers": [{"value": "555-555-8377", "type": "mobile"}],
+			"addresses": [{"streetAddress": "100 Universal City Plaza", "type": "work"}],
+			"ims": [{"value": "someaimhandle", "type": "aim"}]
+		}`), response)
+		user := convertUserResponseToPorcelain(response)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("hr-123", user.ExternalID)
+		assertT.Equal("Babs", user.NickName)
+		assertT.Equal("Ms.", user.Name.HonorificPrefix)
+		assertT.Equal([]models.UserEmail{{Value: "bjensen@example.com", Type: "work", Primary: true}}, user.Emails)
+		assertT.Equal([]models.UserPhoneNumber{{Value: "555-555-8377", Type: "mobile"}}, user.PhoneNumbers)
+		assertT.Equal([]models.UserAddress{{StreetAddress: "100 Universal City Plaza", Type: "work"}}, user.Addresses)
+		assertT.Equal([]models.UserAttribute{{Value: "someaimhandle", Type: "aim"}}, user.IMs)
+		assertT.True(filter.Match(filter.Eq("phoneNumbers.type", "mobile"), user))
+	})
+}
+
+func TestConvertUserMetadataToPorcelain(t *testing.T) {
+	t.Run("should convert the user metadata", func(t *testing.T) {
+		lastModified := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+		response := &service.UserResponse{
+			ID: "xxx",
+			Meta: &service.MetadataResponse{
+				ResourceType: "User",
+				Created:      lastModified.Add(-time.Hour),
+				LastModified: lastModified,
+				Version:      `W/"3"`,
+			},
+		}
+		user := convertUserResponseToPorcelain(response)
+		assertT := assert.New(t)
+
+		assertT.Equal(&models.Metadata{ResourceType: "User", Created: lastModified.Add(-time.Hour), LastModified: lastModified, Version: `W/"3"`}, user.Meta)
+		assertT.True(filter.Match(filter.Gt("meta.lastModified", lastModified.Add(-time.Minute)), user))
+		assertT.False(filter.Match(filter.Gt("meta.lastModified", lastModified), user))
+	})
+
+	t.Run("should leave the metadata empty when the server doesn't return it", func(t *testing.T) {
+		user := convertUserResponseToPorcelain(&service.UserResponse{ID: "xxx"})
+
+		assert.Nil(t, user.Meta)
+	})
+}
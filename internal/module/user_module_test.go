@@ -3,13 +3,19 @@ package module
 import (
 	"bytes"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
+	"log/slog"
 	"net/http"
+	"net/url"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/strongdm/scimsdk/filter"
+	"github.com/strongdm/scimsdk/internal/api"
 	"github.com/strongdm/scimsdk/internal/service"
 	"github.com/strongdm/scimsdk/models"
 )
@@ -19,7 +25,7 @@ const mockUsersPageSize = 2
 func TestUsersServiceListIterator(t *testing.T) {
 	t.Run("should return an users iteartor when there's no pagination options", func(t *testing.T) {
 		mockApi := getMockedAPI(mockedApiExecuteWithUserPageResponse)
-		serviceApi := service.NewUserService(mockApi, "token")
+		serviceApi := service.NewUserService(mockApi, api.NewStaticTokenSource("token"))
 		module := NewMockUserModule(serviceApi)
 		iterator := module.List(context.Background(), nil)
 		assertT := assert.New(t)
@@ -38,7 +44,7 @@ func TestUsersServiceListIterator(t *testing.T) {
 
 	t.Run("should return an users iterator when there's pagination options", func(t *testing.T) {
 		mockApi := getMockedAPI(mockedApiExecuteWithUserPageResponse)
-		serviceApi := service.NewUserService(mockApi, "token")
+		serviceApi := service.NewUserService(mockApi, api.NewStaticTokenSource("token"))
 		module := NewMockUserModule(serviceApi)
 		opts := &models.PaginationOptions{PageSize: mockUsersPageSize, Offset: 1}
 		iterator := module.List(context.Background(), opts)
@@ -59,7 +65,7 @@ func TestUsersServiceListIterator(t *testing.T) {
 
 	t.Run("should return an empty users iterator when the offset is greater than page size and users count", func(t *testing.T) {
 		mockApi := getMockedAPI(mockedApiExecuteWithUserPageResponse)
-		serviceApi := service.NewUserService(mockApi, "token")
+		serviceApi := service.NewUserService(mockApi, api.NewStaticTokenSource("token"))
 		module := NewMockUserModule(serviceApi)
 		opts := &models.PaginationOptions{PageSize: mockUsersPageSize, Offset: 3}
 		iterator := module.List(context.Background(), opts)
@@ -74,6 +80,173 @@ func TestUsersServiceListIterator(t *testing.T) {
 	})
 }
 
+func TestUsersListFilterValidation(t *testing.T) {
+	t.Run("should return a syntax error without sending the request when the filter is invalid", func(t *testing.T) {
+		requests := 0
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			requests++
+			return mockedApiExecuteWithUserPageResponse(request)
+		})
+		serviceApi := service.NewUserService(mockApi, api.NewStaticTokenSource("token"))
+		module := NewMockUserModule(serviceApi)
+		iterator := module.List(context.Background(), &models.PaginationOptions{Filter: `userName eq "john" or`})
+		var syntaxErr *filter.SyntaxError
+		assertT := assert.New(t)
+
+		assertT.False(iterator.Next())
+		assertT.True(errors.As(iterator.Err(), &syntaxErr))
+		assertT.Equal(21, syntaxErr.Offset)
+		assertT.Zero(requests)
+	})
+}
+
+func TestUsersListFilterValuePathComparison(t *testing.T) {
+	t.Run("should send a value path sub-attribute comparison unchanged", func(t *testing.T) {
+		var queries []string
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			queries = append(queries, request.URL.Query().Get("filter"))
+			return mockedApiExecuteWithUserPageResponse(request)
+		})
+		serviceApi := service.NewUserService(mockApi, api.NewStaticTokenSource("token"))
+		module := NewMockUserModule(serviceApi)
+		iterator := module.List(context.Background(), &models.PaginationOptions{Filter: `emails[type eq "work"].value pr`})
+		iterator.Next()
+		assertT := assert.New(t)
+
+		assertT.Nil(iterator.Err())
+		assertT.NotEmpty(queries)
+		assertT.Equal(`emails[type eq "work"].value pr`, queries[0])
+	})
+}
+
+func TestUsersListSearch(t *testing.T) {
+	t.Run("should fetch every page with a search when it's requested", func(t *testing.T) {
+		var requests []string
+		var searchRequests []*api.SearchRequest
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			requests = append(requests, request.Method+" "+request.URL.Path)
+			searchRequest := &api.SearchRequest{}
+			json.NewDecoder(request.Body).Decode(searchRequest)
+			searchRequests = append(searchRequests, searchRequest)
+			pageCount := fmt.Sprint(searchRequest.Count)
+			body := getUsersPageResponseJSON(pageCount)
+			if searchRequest.StartIndex > searchRequest.Count {
+				body = getEmptyUsersPageResponseJSON(pageCount)
+			}
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		})
+		serviceApi := service.NewUserService(mockApi, api.NewStaticTokenSource("token"))
+		module := NewMockUserModule(serviceApi)
+		opts := &models.PaginationOptions{PageSize: mockUsersPageSize, FilterExpression: filter.Eq("emails.value", "user@example.com"), Search: true}
+		iterator := module.List(context.Background(), opts)
+		users := 0
+		for iterator.Next() {
+			users++
+		}
+		assertT := assert.New(t)
+
+		assertT.Nil(iterator.Err())
+		assertT.Equal(2, users)
+		assertT.Len(requests, 2)
+		for _, request := range requests {
+			assertT.Equal("POST /provisioning/generic/v2/Users/.search", request)
+		}
+		assertT.Equal(`emails.value eq "user@example.com"`, searchRequests[0].Filter)
+		assertT.Equal(1, searchRequests[0].StartIndex)
+		assertT.Equal(3, searchRequests[1].StartIndex)
+	})
+}
+
+func TestUsersPatch(t *testing.T) {
+	t.Run("should send every operation in a single PATCH request", func(t *testing.T) {
+		var requests []string
+		var body map[string]interface{}
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			requests = append(requests, request.Method+" "+request.URL.Path)
+			json.NewDecoder(request.Body).Decode(&body)
+			return &http.Response{StatusCode: http.StatusNoContent, Body: ioutil.NopCloser(strings.NewReader(""))}, nil
+		})
+		module := NewMockUserModule(service.NewUserService(mockApi, api.NewStaticTokenSource("token")))
+		ok, err := module.Patch(context.Background(), "user-id", models.NewUserPatch().Replace("userName", "jane@example.com").Remove("nickName"))
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.True(ok)
+		assertT.Equal([]string{"PATCH /provisioning/generic/v2/Users/user-id"}, requests)
+		assertT.Len(body["Operations"], 2)
+	})
+}
+
+func TestUsersFindByExternalID(t *testing.T) {
+	t.Run("should find the user filtered by its external id", func(t *testing.T) {
+		var query url.Values
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			query = request.URL.Query()
+			body := fmt.Sprintf(`{"Resources": [%s], "itemsPerPage": 2, "startIndex": 1, "totalResults": 1}`, getUserResponseJSON())
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		})
+		module := NewMockUserModule(service.NewUserService(mockApi, api.NewStaticTokenSource("token")))
+		user, err := module.FindByExternalID(context.Background(), "hr-123")
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("a-xxx", user.ID)
+		assertT.Equal(`externalId eq "hr-123"`, query.Get("filter"))
+	})
+
+	t.Run("should return a not found error when no user has the external id", func(t *testing.T) {
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(getEmptyUsersPageResponseJSON("2")))}, nil
+		})
+		module := NewMockUserModule(service.NewUserService(mockApi, api.NewStaticTokenSource("token")))
+		user, err := module.FindByExternalID(context.Background(), "hr-123")
+		assertT := assert.New(t)
+
+		assertT.Nil(user)
+		assertT.True(errors.Is(err, api.ErrNotFound))
+	})
+
+	t.Run("should return an error when several users have the external id", func(t *testing.T) {
+		mockApi := getMockedAPI(func(request *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(getUsersPageResponseJSON("2")))}, nil
+		})
+		module := NewMockUserModule(service.NewUserService(mockApi, api.NewStaticTokenSource("token")))
+		_, err := module.FindByExternalID(context.Background(), "hr-123")
+
+		assert.EqualError(t, err, `more than one user has the external id "hr-123"`)
+	})
+
+	t.Run("should return an error when passing an empty external id", func(t *testing.T) {
+		module := NewMockUserModule(service.NewUserService(getMockedAPI(nil), api.NewStaticTokenSource("token")))
+		_, err := module.FindByExternalID(context.Background(), "")
+
+		assert.EqualError(t, err, "you must pass the user external id")
+	})
+}
+
+func TestUsersListIteratorLogging(t *testing.T) {
+	t.Run("should log each page fetched by the iterator", func(t *testing.T) {
+		output := &bytes.Buffer{}
+		logger := slog.New(slog.NewTextHandler(output, &slog.HandlerOptions{Level: slog.LevelDebug}))
+		mockApi := getMockedAPI(mockedApiExecuteWithUserPageResponse)
+		serviceApi := service.NewUserService(mockApi, api.NewStaticTokenSource("token"))
+		module := NewUserModule(serviceApi, "", Instrumentation{Logger: logger}, nil)
+		iterator := module.List(context.Background(), &models.PaginationOptions{PageSize: mockUsersPageSize, Offset: 1})
+		for iterator.Next() {
+		}
+		logs := output.String()
+		assertT := assert.New(t)
+
+		assertT.Nil(iterator.Err())
+		assertT.Contains(logs, "scim page fetched")
+		assertT.Contains(logs, "operation=Users.List")
+		assertT.Contains(logs, "offset=1")
+		assertT.Contains(logs, "items=2")
+		assertT.Contains(logs, "offset=3")
+		assertT.Contains(logs, "items=0")
+	})
+}
+
 func mockedApiExecuteWithUserPageResponse(request *http.Request) (*http.Response, error) {
 	token := extractAuthorizationToken(request.Header.Get("Authorization"))
 	if token == "" {
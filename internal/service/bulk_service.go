@@ -0,0 +1,88 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/strongdm/scimsdk/internal/api"
+)
+
+type BulkService interface {
+	Execute(ctx context.Context, opts *CreateOptions) (*BulkResponse, error)
+	// ExecuteOperation executes a single bulk operation with its resource
+	// endpoint, for the servers that don't support the Bulk endpoint
+	ExecuteOperation(ctx context.Context, opts *BulkOperationOptions) (*BulkOperationResponse, error)
+}
+
+type BulkOperationOptions struct {
+	Method     string
+	BulkID     string
+	Pathname   string
+	ID         string
+	Body       interface{}
+	BaseAPIURL string
+}
+
+type bulkServiceImpl struct {
+	client      api.API
+	tokenSource api.TokenSource
+}
+
+const bulkAPIPathname = "Bulk"
+
+func NewBulkService(api api.API, tokenSource api.TokenSource) BulkService {
+	return &bulkServiceImpl{api, tokenSource}
+}
+
+func (service *bulkServiceImpl) Execute(ctx context.Context, opts *CreateOptions) (*BulkResponse, error) {
+	bulkResponse := &BulkResponse{}
+	_, err := service.client.Create(ctx, bulkAPIPathname, service.tokenSource, newAPICreateOptions(opts), bulkResponse)
+	if err != nil {
+		return nil, err
+	}
+	return bulkResponse, nil
+}
+
+func (service *bulkServiceImpl) ExecuteOperation(ctx context.Context, opts *BulkOperationOptions) (*BulkOperationResponse, error) {
+	resourceResponse := &BulkResourceResponse{}
+	var response *api.Response
+	var err error
+	switch opts.Method {
+	case http.MethodPost:
+		response, err = service.client.Create(ctx, opts.Pathname, service.tokenSource, api.NewCreateOptions(opts.Body, opts.BaseAPIURL), resourceResponse)
+	case http.MethodPut:
+		response, err = service.client.Replace(ctx, opts.Pathname, service.tokenSource, api.NewReplaceOptions(opts.ID, opts.Body, opts.BaseAPIURL), resourceResponse)
+	case http.MethodPatch:
+		response, err = service.client.Update(ctx, opts.Pathname, service.tokenSource, api.NewUpdateOptions(opts.ID, opts.Body, opts.BaseAPIURL), nil)
+	case http.MethodDelete:
+		response, err = service.client.Delete(ctx, opts.Pathname, service.tokenSource, api.NewDeleteOptions(opts.ID, opts.BaseAPIURL))
+	default:
+		return nil, fmt.Errorf("invalid bulk operation method %q", opts.Method)
+	}
+	if err != nil {
+		return nil, err
+	}
+	operationResponse := &BulkOperationResponse{
+		Method:   opts.Method,
+		BulkID:   opts.BulkID,
+		Location: response.Header.Get("Location"),
+		Version:  response.Header.Get("ETag"),
+		Status:   BulkStatusResponse(response.StatusCode),
+	}
+	if resourceResponse.Meta != nil {
+		if operationResponse.Location == "" {
+			operationResponse.Location = resourceResponse.Meta.Location
+		}
+		if operationResponse.Version == "" {
+			operationResponse.Version = resourceResponse.Meta.Version
+		}
+	}
+	if resourceResponse.ID != "" {
+		if operationResponse.Response, err = json.Marshal(resourceResponse); err != nil {
+			return nil, err
+		}
+	}
+	return operationResponse, nil
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"encoding/json"
+	"strconv"
+)
+
+type BulkRequest struct {
+	Schemas      []string                `json:"schemas"`
+	FailOnErrors int                     `json:"failOnErrors,omitempty"`
+	Operations   []*BulkOperationRequest `json:"Operations"`
+}
+
+type BulkOperationRequest struct {
+	Method string          `json:"method"`
+	BulkID string          `json:"bulkId,omitempty"`
+	Path   string          `json:"path"`
+	Data   json.RawMessage `json:"data,omitempty"`
+}
+
+type BulkResponse struct {
+	Schemas    []string                 `json:"schemas"`
+	Operations []*BulkOperationResponse `json:"Operations"`
+}
+
+type BulkOperationResponse struct {
+	Method   string             `json:"method"`
+	BulkID   string             `json:"bulkId"`
+	Version  string             `json:"version"`
+	Location string             `json:"location"`
+	Status   BulkStatusResponse `json:"status"`
+	Response json.RawMessage    `json:"response"`
+}
+
+// BulkStatusResponse is the http status of a bulk operation, which the
+// servers send either as a number, a string or an object with its code.
+type BulkStatusResponse int
+
+func (status *BulkStatusResponse) UnmarshalJSON(data []byte) error {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	if object, ok := value.(map[string]interface{}); ok {
+		value = object["code"]
+	}
+	switch typedValue := value.(type) {
+	case float64:
+		*status = BulkStatusResponse(typedValue)
+	case string:
+		code, err := strconv.Atoi(typedValue)
+		if err != nil {
+			return err
+		}
+		*status = BulkStatusResponse(code)
+	}
+	return nil
+}
+
+// BulkResourceResponse holds the attributes of a resource created or replaced
+// by a bulk operation executed without the Bulk endpoint.
+type BulkResourceResponse struct {
+	ID   string                        `json:"id"`
+	Meta *BulkResourceMetadataResponse `json:"meta"`
+}
+
+type BulkResourceMetadataResponse struct {
+	Location string `json:"location"`
+	Version  string `json:"version"`
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/strongdm/scimsdk/internal/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBulkServiceExecute(t *testing.T) {
+	t.Run("should return the operation responses with any status format", func(t *testing.T) {
+		mock := api.NewMockAPI(func(request *http.Request) (*http.Response, error) {
+			body := `{"Operations": [{"status": "201"}, {"status": 200}, {"status": {"code": "409"}, "response": {"scimType": "uniqueness"}}]}`
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		})
+		service := NewBulkService(mock, api.NewStaticTokenSource("token"))
+		response, err := service.Execute(context.Background(), &CreateOptions{Body: &BulkRequest{}})
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Len(response.Operations, 3)
+		assertT.Equal(BulkStatusResponse(201), response.Operations[0].Status)
+		assertT.Equal(BulkStatusResponse(200), response.Operations[1].Status)
+		assertT.Equal(BulkStatusResponse(409), response.Operations[2].Status)
+		assertT.JSONEq(`{"scimType": "uniqueness"}`, string(response.Operations[2].Response))
+	})
+}
+
+func TestBulkServiceExecuteOperation(t *testing.T) {
+	t.Run("should execute the operation with its resource endpoint", func(t *testing.T) {
+		var method, path string
+		mock := api.NewMockAPI(func(request *http.Request) (*http.Response, error) {
+			method, path = request.Method, request.URL.Path
+			body := `{"id": "user-id", "meta": {"location": "https://example.com/Users/user-id", "version": "W/\"1\""}}`
+			return &http.Response{StatusCode: http.StatusCreated, Header: http.Header{}, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		})
+		service := NewBulkService(mock, api.NewStaticTokenSource("token"))
+		response, err := service.ExecuteOperation(context.Background(), &BulkOperationOptions{
+			Method:   http.MethodPost,
+			BulkID:   "user",
+			Pathname: "Users",
+			Body:     json.RawMessage(`{"userName": "user@example.com"}`),
+		})
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal(http.MethodPost, method)
+		assertT.True(strings.HasSuffix(path, "/Users"))
+		assertT.Equal(BulkStatusResponse(http.StatusCreated), response.Status)
+		assertT.Equal("user", response.BulkID)
+		assertT.Equal("https://example.com/Users/user-id", response.Location)
+		assertT.Equal(`W/"1"`, response.Version)
+		assertT.JSONEq(`{"id": "user-id", "meta": {"location": "https://example.com/Users/user-id", "version": "W/\"1\""}}`, string(response.Response))
+	})
+}
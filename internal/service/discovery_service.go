@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+
+	"github.com/strongdm/scimsdk/internal/api"
+)
+
+type DiscoveryService interface {
+	FindServiceProviderConfig(ctx context.Context, opts *FindOptions) (*ServiceProviderConfigResponse, error)
+	FindResourceTypes(ctx context.Context, opts *FindOptions) ([]*ResourceTypeResponse, error)
+	FindSchemas(ctx context.Context, opts *FindOptions) ([]*SchemaResponse, error)
+}
+
+type discoveryServiceImpl struct {
+	client      api.API
+	tokenSource api.TokenSource
+}
+
+const (
+	serviceProviderConfigAPIPathname = "ServiceProviderConfig"
+	resourceTypesAPIPathname         = "ResourceTypes"
+	schemasAPIPathname               = "Schemas"
+)
+
+func NewDiscoveryService(api api.API, tokenSource api.TokenSource) DiscoveryService {
+	return &discoveryServiceImpl{api, tokenSource}
+}
+
+func (service *discoveryServiceImpl) FindServiceProviderConfig(ctx context.Context, opts *FindOptions) (*ServiceProviderConfigResponse, error) {
+	configResponse := &ServiceProviderConfigResponse{}
+	_, err := service.client.Find(ctx, serviceProviderConfigAPIPathname, service.tokenSource, newAPIFindOptions(opts), configResponse)
+	if err != nil {
+		return nil, err
+	}
+	return configResponse, nil
+}
+
+func (service *discoveryServiceImpl) FindResourceTypes(ctx context.Context, opts *FindOptions) ([]*ResourceTypeResponse, error) {
+	resourceTypesResponse := &ListOrArrayResponse[ResourceTypeResponse]{}
+	_, err := service.client.Find(ctx, resourceTypesAPIPathname, service.tokenSource, newAPIFindOptions(opts), resourceTypesResponse)
+	if err != nil {
+		return nil, err
+	}
+	return resourceTypesResponse.Resources, nil
+}
+
+func (service *discoveryServiceImpl) FindSchemas(ctx context.Context, opts *FindOptions) ([]*SchemaResponse, error) {
+	schemasResponse := &ListOrArrayResponse[SchemaResponse]{}
+	_, err := service.client.Find(ctx, schemasAPIPathname, service.tokenSource, newAPIFindOptions(opts), schemasResponse)
+	if err != nil {
+		return nil, err
+	}
+	return schemasResponse.Resources, nil
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+type ServiceProviderConfigResponse struct {
+	Schemas               []string                        `json:"schemas"`
+	DocumentationURI      string                          `json:"documentationUri"`
+	Patch                 *SupportedFeatureResponse       `json:"patch"`
+	Bulk                  *BulkFeatureResponse            `json:"bulk"`
+	Filter                *FilterFeatureResponse          `json:"filter"`
+	ChangePassword        *SupportedFeatureResponse       `json:"changePassword"`
+	Sort                  *SupportedFeatureResponse       `json:"sort"`
+	ETag                  *SupportedFeatureResponse       `json:"etag"`
+	AuthenticationSchemes []*AuthenticationSchemeResponse `json:"authenticationSchemes"`
+}
+
+type SupportedFeatureResponse struct {
+	Supported bool `json:"supported"`
+}
+
+type BulkFeatureResponse struct {
+	Supported      bool `json:"supported"`
+	MaxOperations  int  `json:"maxOperations"`
+	MaxPayloadSize int  `json:"maxPayloadSize"`
+}
+
+type FilterFeatureResponse struct {
+	Supported  bool `json:"supported"`
+	MaxResults int  `json:"maxResults"`
+}
+
+type AuthenticationSchemeResponse struct {
+	Type             string `json:"type"`
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	SpecURI          string `json:"specUri"`
+	DocumentationURI string `json:"documentationUri"`
+	Primary          bool   `json:"primary"`
+}
+
+// ListOrArrayResponse decodes the discovery endpoints that return either a
+// ListResponse or a plain JSON array of resources.
+type ListOrArrayResponse[T interface{}] struct {
+	Resources []*T
+}
+
+func (response *ListOrArrayResponse[T]) UnmarshalJSON(body []byte) error {
+	if trimmedBody := bytes.TrimSpace(body); len(trimmedBody) > 0 && trimmedBody[0] == '[' {
+		return json.Unmarshal(trimmedBody, &response.Resources)
+	}
+	listResponse := struct {
+		Resources []*T `json:"Resources"`
+	}{}
+	if err := json.Unmarshal(body, &listResponse); err != nil {
+		return err
+	}
+	response.Resources = listResponse.Resources
+	return nil
+}
+
+type ResourceTypeResponse struct {
+	Schemas          []string                   `json:"schemas"`
+	ID               string                     `json:"id"`
+	Name             string                     `json:"name"`
+	Description      string                     `json:"description"`
+	Endpoint         string                     `json:"endpoint"`
+	Schema           string                     `json:"schema"`
+	SchemaExtensions []*SchemaExtensionResponse `json:"schemaExtensions"`
+}
+
+type SchemaExtensionResponse struct {
+	Schema   string `json:"schema"`
+	Required bool   `json:"required"`
+}
+
+type SchemaResponse struct {
+	ID          string                     `json:"id"`
+	Name        string                     `json:"name"`
+	Description string                     `json:"description"`
+	Attributes  []*SchemaAttributeResponse `json:"attributes"`
+}
+
+type SchemaAttributeResponse struct {
+	Name            string                     `json:"name"`
+	Type            string                     `json:"type"`
+	MultiValued     bool                       `json:"multiValued"`
+	Description     string                     `json:"description"`
+	Required        bool                       `json:"required"`
+	CanonicalValues []string                   `json:"canonicalValues"`
+	CaseExact       bool                       `json:"caseExact"`
+	Mutability      string                     `json:"mutability"`
+	Returned        string                     `json:"returned"`
+	Uniqueness      string                     `json:"uniqueness"`
+	ReferenceTypes  []string                   `json:"referenceTypes"`
+	SubAttributes   []*SchemaAttributeResponse `json:"subAttributes"`
+}
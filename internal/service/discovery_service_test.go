@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/strongdm/scimsdk/internal/api"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscoveryServiceFindServiceProviderConfig(t *testing.T) {
+	t.Run("should return the service provider config", func(t *testing.T) {
+		mock := api.NewMockAPI(func(request *http.Request) (*http.Response, error) {
+			body := `{"sort": {"supported": true}, "bulk": {"supported": true, "maxOperations": 10, "maxPayloadSize": 1024}}`
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		})
+		service := NewDiscoveryService(mock, api.NewStaticTokenSource("token"))
+		config, err := service.FindServiceProviderConfig(context.Background(), &FindOptions{})
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.True(config.Sort.Supported)
+		assertT.Equal(10, config.Bulk.MaxOperations)
+		assertT.Nil(config.ETag)
+	})
+}
+
+func TestDiscoveryServiceFindResourceTypes(t *testing.T) {
+	t.Run("should return the resource types of a list response", func(t *testing.T) {
+		mock := api.NewMockAPI(func(request *http.Request) (*http.Response, error) {
+			body := `{"totalResults": 1, "Resources": [{"id": "User", "name": "User", "endpoint": "/Users", "schema": "urn:ietf:params:scim:schemas:core:2.0:User"}]}`
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		})
+		service := NewDiscoveryService(mock, api.NewStaticTokenSource("token"))
+		resourceTypes, err := service.FindResourceTypes(context.Background(), &FindOptions{})
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Len(resourceTypes, 1)
+		assertT.Equal("/Users", resourceTypes[0].Endpoint)
+	})
+
+	t.Run("should return the resource types of an array response", func(t *testing.T) {
+		mock := api.NewMockAPI(func(request *http.Request) (*http.Response, error) {
+			body := `[{"id": "Group", "name": "Group", "endpoint": "/Groups"}]`
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		})
+		service := NewDiscoveryService(mock, api.NewStaticTokenSource("token"))
+		resourceTypes, err := service.FindResourceTypes(context.Background(), &FindOptions{})
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Len(resourceTypes, 1)
+		assertT.Equal("Group", resourceTypes[0].ID)
+	})
+}
+
+func TestDiscoveryServiceFindSchemas(t *testing.T) {
+	t.Run("should return the schemas with their sub-attributes", func(t *testing.T) {
+		mock := api.NewMockAPI(func(request *http.Request) (*http.Response, error) {
+			body := `{"Resources": [{"id": "urn:ietf:params:scim:schemas:core:2.0:User", "attributes": [{"name": "name", "type": "complex", "subAttributes": [{"name": "givenName", "type": "string"}]}]}]}`
+			return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(strings.NewReader(body))}, nil
+		})
+		service := NewDiscoveryService(mock, api.NewStaticTokenSource("token"))
+		schemas, err := service.FindSchemas(context.Background(), &FindOptions{})
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Len(schemas, 1)
+		assertT.Equal("givenName", schemas[0].Attributes[0].SubAttributes[0].Name)
+	})
+}
@@ -0,0 +1,60 @@
+package service
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+const (
+	coreUserSchema       = "urn:ietf:params:scim:schemas:core:2.0:User"
+	coreGroupSchema      = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	enterpriseUserSchema = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+)
+
+// unmarshalExtensions returns the attributes of the body named by a schema
+// URI, which hold the schema extensions, except the excluded schemas.
+func unmarshalExtensions(body []byte, excludedSchemas ...string) (map[string]json.RawMessage, error) {
+	attributes := map[string]json.RawMessage{}
+	if err := json.Unmarshal(body, &attributes); err != nil {
+		return nil, err
+	}
+	var extensions map[string]json.RawMessage
+	for name, value := range attributes {
+		if !strings.Contains(name, ":") || containsFold(excludedSchemas, name) {
+			continue
+		}
+		if extensions == nil {
+			extensions = map[string]json.RawMessage{}
+		}
+		extensions[name] = value
+	}
+	return extensions, nil
+}
+
+// marshalWithExtensions encodes the value adding the attributes of the
+// extensions, named by their schema URI.
+func marshalWithExtensions(value interface{}, extensions map[string]interface{}) ([]byte, error) {
+	body, err := json.Marshal(value)
+	if err != nil || len(extensions) == 0 {
+		return body, err
+	}
+	attributes := map[string]interface{}{}
+	if err := json.Unmarshal(body, &attributes); err != nil {
+		return nil, err
+	}
+	for schema, extension := range extensions {
+		if _, ok := attributes[schema]; !ok {
+			attributes[schema] = extension
+		}
+	}
+	return json.Marshal(attributes)
+}
+
+func containsFold(values []string, value string) bool {
+	for _, item := range values {
+		if strings.EqualFold(item, value) {
+			return true
+		}
+	}
+	return false
+}
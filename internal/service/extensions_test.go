@@ -0,0 +1,63 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUserResponseExtensions(t *testing.T) {
+	t.Run("should keep the attributes of the unknown schema extensions", func(t *testing.T) {
+		body := `{
+			"id": "user-id",
+			"schemas": ["urn:ietf:params:scim:schemas:core:2.0:User", "urn:example:badge"],
+			"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User": {"department": "Engineering"},
+			"urn:example:badge": {"badgeId": "42"}
+		}`
+		response := &UserResponse{}
+		err := json.Unmarshal([]byte(body), response)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("user-id", response.ID)
+		assertT.Equal("Engineering", response.EnterpriseUser.Department)
+		assertT.Len(response.Extensions, 1)
+		assertT.JSONEq(`{"badgeId": "42"}`, string(response.Extensions["urn:example:badge"]))
+	})
+
+	t.Run("should not set the extensions when there are none", func(t *testing.T) {
+		response := &GroupResponse{}
+		err := json.Unmarshal([]byte(`{"id": "group-id"}`), response)
+
+		assert.Nil(t, err)
+		assert.Nil(t, response.Extensions)
+	})
+}
+
+func TestCreateRequestExtensions(t *testing.T) {
+	t.Run("should add the extension attributes to the request body", func(t *testing.T) {
+		request := &CreateUserRequest{
+			UserName:   "user@example.com",
+			Extensions: map[string]interface{}{"urn:example:badge": map[string]string{"badgeId": "42"}},
+		}
+		body, err := json.Marshal(request)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Contains(string(body), `"urn:example:badge":{"badgeId":"42"}`)
+		assertT.Contains(string(body), `"userName":"user@example.com"`)
+		assertT.NotContains(string(body), "Extensions")
+	})
+
+	t.Run("should add the extension attributes to the replace group body", func(t *testing.T) {
+		request := &ReplaceGroupRequest{
+			DisplayName: "Group",
+			Extensions:  map[string]interface{}{"urn:example:cost": map[string]int{"center": 7}},
+		}
+		body, err := json.Marshal(request)
+
+		assert.Nil(t, err)
+		assert.Contains(t, string(body), `"urn:example:cost":{"center":7}`)
+	})
+}
@@ -2,6 +2,7 @@ package service
 
 import (
 	"context"
+	"net/http"
 
 	"github.com/strongdm/scimsdk/internal/api"
 )
@@ -12,34 +13,36 @@ type GroupService interface {
 	Find(ctx context.Context, opts *FindOptions) (*GroupResponse, error)
 	Replace(ctx context.Context, opts *ReplaceOptions) (*GroupResponse, error)
 	Update(ctx context.Context, opts *UpdateOptions) (bool, error)
+	// Patch updates the group returning it, or nil when the server doesn't
+	// send it in the response
+	Patch(ctx context.Context, opts *UpdateOptions) (*GroupResponse, error)
 	Delete(ctx context.Context, opts *DeleteOptions) (bool, error)
 }
 
 type groupServiceImpl struct {
-	client api.API
-	token  string
+	client      api.API
+	tokenSource api.TokenSource
 }
 
 const groupsAPIPathname = "Groups"
 
-func NewGroupService(api api.API, token string) GroupService {
-	return &groupServiceImpl{api, token}
+func NewGroupService(api api.API, tokenSource api.TokenSource) GroupService {
+	return &groupServiceImpl{api, tokenSource}
 }
 
 func (service *groupServiceImpl) Create(ctx context.Context, opts *CreateOptions) (*GroupResponse, error) {
-	response, err := service.client.Create(ctx, groupsAPIPathname, service.token, newAPICreateOptions(opts))
+	groupResponse := &GroupResponse{}
+	response, err := service.client.Create(ctx, groupsAPIPathname, service.tokenSource, newAPICreateOptions(opts), groupResponse)
 	if err != nil {
 		return nil, err
 	}
-	return unmarshalGroupResponse(response.Body)
+	setMetadataVersion(&groupResponse.Meta, response)
+	return groupResponse, nil
 }
 
 func (service *groupServiceImpl) List(ctx context.Context, opts *ListOptions) ([]*GroupResponse, bool, error) {
-	response, err := service.client.List(ctx, groupsAPIPathname, service.token, newAPIListOptions(opts))
-	if err != nil {
-		return nil, false, err
-	}
-	groupPageResponse, err := unmarshalGroupPageResponse(response.Body)
+	groupPageResponse := &GroupPageResponse{}
+	_, err := service.client.List(ctx, groupsAPIPathname, service.tokenSource, newAPIListOptions(opts), groupPageResponse)
 	if err != nil {
 		return nil, false, err
 	}
@@ -47,27 +50,43 @@ func (service *groupServiceImpl) List(ctx context.Context, opts *ListOptions) ([
 }
 
 func (service *groupServiceImpl) Find(ctx context.Context, opts *FindOptions) (*GroupResponse, error) {
-	response, err := service.client.Find(ctx, groupsAPIPathname, service.token, newAPIFindOptions(opts))
+	groupResponse := &GroupResponse{}
+	response, err := service.client.Find(ctx, groupsAPIPathname, service.tokenSource, newAPIFindOptions(opts), groupResponse)
 	if err != nil {
 		return nil, err
 	}
-	return unmarshalGroupResponse(response.Body)
+	setMetadataVersion(&groupResponse.Meta, response)
+	return groupResponse, nil
 }
 
 func (service *groupServiceImpl) Replace(ctx context.Context, opts *ReplaceOptions) (*GroupResponse, error) {
-	response, err := service.client.Replace(ctx, groupsAPIPathname, service.token, newAPIReplaceOptions(opts))
+	groupResponse := &GroupResponse{}
+	response, err := service.client.Replace(ctx, groupsAPIPathname, service.tokenSource, newAPIReplaceOptions(opts), groupResponse)
 	if err != nil {
 		return nil, err
 	}
-	return unmarshalGroupResponse(response.Body)
+	setMetadataVersion(&groupResponse.Meta, response)
+	return groupResponse, nil
 }
 
 func (service *groupServiceImpl) Update(ctx context.Context, opts *UpdateOptions) (bool, error) {
-	_, err := service.client.Update(ctx, groupsAPIPathname, service.token, newAPIUpdateOptions(opts))
+	_, err := service.client.Update(ctx, groupsAPIPathname, service.tokenSource, newAPIUpdateOptions(opts), nil)
 	return err == nil, err
 }
 
+func (service *groupServiceImpl) Patch(ctx context.Context, opts *UpdateOptions) (*GroupResponse, error) {
+	groupResponse := &GroupResponse{}
+	response, err := service.client.Update(ctx, groupsAPIPathname, service.tokenSource, newAPIUpdateOptions(opts), groupResponse)
+	if err != nil {
+		return nil, err
+	} else if response.StatusCode == http.StatusNoContent || groupResponse.ID == "" {
+		return nil, nil
+	}
+	setMetadataVersion(&groupResponse.Meta, response)
+	return groupResponse, nil
+}
+
 func (service *groupServiceImpl) Delete(ctx context.Context, opts *DeleteOptions) (bool, error) {
-	_, err := service.client.Delete(ctx, groupsAPIPathname, service.token, newAPIDeleteOptions(opts))
+	_, err := service.client.Delete(ctx, groupsAPIPathname, service.tokenSource, newAPIDeleteOptions(opts))
 	return err == nil, err
 }
@@ -1,5 +1,7 @@
 package service
 
+import "encoding/json"
+
 type GroupPageResponse struct {
 	Resources    []*GroupResponse `json:"Resources"`
 	ItemsPerPage int              `json:"itemsPerPage"`
@@ -12,8 +14,21 @@ type GroupResponse struct {
 	Schemas     []string               `json:"schemas"`
 	DisplayName string                 `json:"displayName"`
 	ID          string                 `json:"id"`
+	ExternalID  string                 `json:"externalId"`
 	Members     []*GroupMemberResponse `json:"members"`
-	Meta        *GroupMetadataResponse `json:"meta"`
+	Meta        *MetadataResponse      `json:"meta"`
+	// Extensions are the attributes of the schema extensions
+	Extensions map[string]json.RawMessage `json:"-"`
+}
+
+func (response *GroupResponse) UnmarshalJSON(body []byte) error {
+	type groupResponse GroupResponse
+	if err := json.Unmarshal(body, (*groupResponse)(response)); err != nil {
+		return err
+	}
+	extensions, err := unmarshalExtensions(body, coreGroupSchema)
+	response.Extensions = extensions
+	return err
 }
 
 type GroupMemberResponse struct {
@@ -21,19 +36,25 @@ type GroupMemberResponse struct {
 	Display string `json:"display"`
 }
 
-type GroupMetadataResponse struct {
-	ResourceType string `json:"resourceType"`
-	Location     string `json:"location"`
+type CreateGroupRequest struct {
+	ExternalID  string                 `json:"externalId,omitempty"`
+	DisplayName string                 `json:"displayName"`
+	Members     []*GroupMemberRequest  `json:"members"`
+	Schemas     []string               `json:"schemas"`
+	Extensions  map[string]interface{} `json:"-"`
 }
 
-type CreateGroupRequest struct {
-	DisplayName string                `json:"displayName"`
-	Members     []*GroupMemberRequest `json:"members"`
-	Schemas     []string              `json:"schemas"`
+func (request *CreateGroupRequest) MarshalJSON() ([]byte, error) {
+	type createGroupRequest CreateGroupRequest
+	return marshalWithExtensions((*createGroupRequest)(request), request.Extensions)
 }
 
 type ReplaceGroupRequest CreateGroupRequest
 
+func (request *ReplaceGroupRequest) MarshalJSON() ([]byte, error) {
+	return (*CreateGroupRequest)(request).MarshalJSON()
+}
+
 type GroupMemberRequest struct {
 	Value   string `json:"value"`
 	Display string `json:"display"`
@@ -21,7 +21,7 @@ const mockGroupID = "xxx"
 func TestGroupServiceCreate(t *testing.T) {
 	t.Run("should create a group when passing valid data", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupResponse)
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		group, err := service.Create(context.Background(), &CreateOptions{Body: nil})
 		assertT := assert.New(t)
 
@@ -31,7 +31,7 @@ func TestGroupServiceCreate(t *testing.T) {
 
 	t.Run("should return an error when creating a group without token", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupResponse)
-		service := NewGroupService(mock, "")
+		service := NewGroupService(mock, api.NewStaticTokenSource(""))
 		group, err := service.Create(context.Background(), &CreateOptions{Body: nil})
 		assertT := assert.New(t)
 
@@ -43,7 +43,7 @@ func TestGroupServiceCreate(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupResponse)
 		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
 		defer cancel()
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		group, err := service.Create(ctx, &CreateOptions{Body: nil})
 		assertT := assert.New(t)
 
@@ -56,7 +56,7 @@ func TestGroupServiceCreate(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithExpiredTimeout)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		group, err := service.Create(ctx, &CreateOptions{Body: nil})
 		assertT := assert.New(t)
 
@@ -71,7 +71,7 @@ func TestGroupServiceCreate(t *testing.T) {
 func TestGroupServiceList(t *testing.T) {
 	t.Run("should return a list of groups when there's no pagination options", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupPageResponse)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		groups, haveNextPage, err := service.List(context.Background(), &ListOptions{})
 		assertT := assert.New(t)
 
@@ -83,7 +83,7 @@ func TestGroupServiceList(t *testing.T) {
 
 	t.Run("should return a list of users when the page size is equal or lesser than the groups count", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupPageResponse)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		groups, haveNextPage, err := service.List(context.Background(), &ListOptions{PageSize: mockGroupsPageSize})
 		assertT := assert.New(t)
 
@@ -95,7 +95,7 @@ func TestGroupServiceList(t *testing.T) {
 
 	t.Run("should return an error when passing an empty token", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupPageResponse)
-		service := NewGroupService(mock, "")
+		service := NewGroupService(mock, api.NewStaticTokenSource(""))
 		groups, haveNextPage, err := service.List(context.Background(), &ListOptions{})
 		assertT := assert.New(t)
 
@@ -106,7 +106,7 @@ func TestGroupServiceList(t *testing.T) {
 
 	t.Run("should return a list of groups when usign a context with timeout", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupPageResponse)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
 		groups, haveNextPage, err := service.List(ctx, &ListOptions{})
@@ -121,7 +121,7 @@ func TestGroupServiceList(t *testing.T) {
 
 	t.Run("should return an error when the context timeout exceed", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithExpiredTimeout)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
 		groups, haveNextPage, err := service.List(ctx, &ListOptions{})
@@ -137,7 +137,7 @@ func TestGroupServiceList(t *testing.T) {
 
 	t.Run("should return false in haveNextPage when the page size is greater than the groups count", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupPageResponse)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		_, haveNextPage, _ := service.List(context.Background(), &ListOptions{PageSize: 3})
 		assertT := assert.New(t)
 
@@ -146,7 +146,7 @@ func TestGroupServiceList(t *testing.T) {
 
 	t.Run("should return zero groups when the offset is greater than the page size and the groups count", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupPageResponse)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		groups, haveNextPage, err := service.List(context.Background(), &ListOptions{PageSize: mockGroupsPageSize, Offset: 3})
 		assertT := assert.New(t)
 
@@ -159,7 +159,7 @@ func TestGroupServiceList(t *testing.T) {
 func TestGroupServiceFind(t *testing.T) {
 	t.Run("should return a group when passing a valid group id", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupResponse)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		group, err := service.Find(context.Background(), &FindOptions{ID: mockGroupID})
 		assertT := assert.New(t)
 
@@ -169,7 +169,7 @@ func TestGroupServiceFind(t *testing.T) {
 
 	t.Run("should return an error when passing an invalid token", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupResponse)
-		service := NewGroupService(mock, "")
+		service := NewGroupService(mock, api.NewStaticTokenSource(""))
 		group, err := service.Find(context.Background(), &FindOptions{ID: mockGroupID})
 		assertT := assert.New(t)
 
@@ -179,7 +179,7 @@ func TestGroupServiceFind(t *testing.T) {
 
 	t.Run("should return an error when passing an invalid group id", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupNotFound)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		group, err := service.Find(context.Background(), &FindOptions{ID: "yyy"})
 		assertT := assert.New(t)
 
@@ -192,7 +192,7 @@ func TestGroupServiceFind(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupResponse)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		group, err := service.Find(context.Background(), &FindOptions{ID: mockGroupID})
 		assertT := assert.New(t)
 
@@ -205,7 +205,7 @@ func TestGroupServiceFind(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithExpiredTimeout)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		group, err := service.Find(context.Background(), &FindOptions{ID: mockGroupID})
 		assertT := assert.New(t)
 
@@ -220,8 +220,8 @@ func TestGroupServiceFind(t *testing.T) {
 func TestGroupServiceReplace(t *testing.T) {
 	t.Run("should replace a group when passing a valid group id", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupResponse)
-		service := NewGroupService(mock, "token")
-		group, err := service.Replace(context.Background(), &ReplaceOptions{mockGroupID, nil, ""})
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
+		group, err := service.Replace(context.Background(), &ReplaceOptions{ID: mockGroupID})
 		assertT := assert.New(t)
 
 		assertT.NotNil(group)
@@ -230,8 +230,8 @@ func TestGroupServiceReplace(t *testing.T) {
 
 	t.Run("should return an error when passing an empty group id", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupNotFound)
-		service := NewGroupService(mock, "token")
-		group, err := service.Replace(context.Background(), &ReplaceOptions{"", nil, ""})
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
+		group, err := service.Replace(context.Background(), &ReplaceOptions{})
 		assertT := assert.New(t)
 
 		assertT.Nil(group)
@@ -241,8 +241,8 @@ func TestGroupServiceReplace(t *testing.T) {
 
 	t.Run("should return an error when passing an empty token", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupNotFound)
-		service := NewGroupService(mock, "")
-		group, err := service.Replace(context.Background(), &ReplaceOptions{mockGroupID, nil, ""})
+		service := NewGroupService(mock, api.NewStaticTokenSource(""))
+		group, err := service.Replace(context.Background(), &ReplaceOptions{ID: mockGroupID})
 		assertT := assert.New(t)
 
 		assertT.Nil(group)
@@ -253,8 +253,8 @@ func TestGroupServiceReplace(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupResponse)
 		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
 		defer cancel()
-		service := NewGroupService(mock, "token")
-		group, err := service.Replace(ctx, &ReplaceOptions{"yyy", nil, ""})
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
+		group, err := service.Replace(ctx, &ReplaceOptions{ID: "yyy"})
 		assertT := assert.New(t)
 
 		assertT.NotNil(group)
@@ -266,8 +266,8 @@ func TestGroupServiceReplace(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithExpiredTimeout)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewGroupService(mock, "token")
-		group, err := service.Replace(ctx, &ReplaceOptions{"yyy", nil, ""})
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
+		group, err := service.Replace(ctx, &ReplaceOptions{ID: "yyy"})
 		assertT := assert.New(t)
 
 		assertT.Nil(group)
@@ -281,7 +281,7 @@ func TestGroupServiceReplace(t *testing.T) {
 func TestGroupsServiceUpdate(t *testing.T) {
 	t.Run("should update a group when passing a valid id and replace name body", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupResponse)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Update(context.Background(), &UpdateOptions{ID: mockGroupID, Body: nil})
 		assertT := assert.New(t)
 
@@ -291,7 +291,7 @@ func TestGroupsServiceUpdate(t *testing.T) {
 
 	t.Run("should update a group when passing a valid id and add members body", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupResponse)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Update(context.Background(), &UpdateOptions{ID: mockGroupID, Body: nil})
 		assertT := assert.New(t)
 
@@ -301,7 +301,7 @@ func TestGroupsServiceUpdate(t *testing.T) {
 
 	t.Run("should update a group when passing a valid id and replace members body", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupResponse)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Update(context.Background(), &UpdateOptions{ID: mockGroupID, Body: nil})
 		assertT := assert.New(t)
 
@@ -311,7 +311,7 @@ func TestGroupsServiceUpdate(t *testing.T) {
 
 	t.Run("should update a group when passing a valid id and remove members body", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupResponse)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Update(context.Background(), &UpdateOptions{ID: mockGroupID, Body: nil})
 		assertT := assert.New(t)
 
@@ -321,7 +321,7 @@ func TestGroupsServiceUpdate(t *testing.T) {
 
 	t.Run("should return an error when passing an invalid token", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupResponse)
-		service := NewGroupService(mock, "")
+		service := NewGroupService(mock, api.NewStaticTokenSource(""))
 		ok, err := service.Update(context.Background(), &UpdateOptions{ID: mockGroupID, Body: nil})
 		assertT := assert.New(t)
 
@@ -331,7 +331,7 @@ func TestGroupsServiceUpdate(t *testing.T) {
 
 	t.Run("should return an error when passing an empty group-id", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupNotFound)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Update(context.Background(), &UpdateOptions{ID: "", Body: nil})
 		assertT := assert.New(t)
 
@@ -343,7 +343,7 @@ func TestGroupsServiceUpdate(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupResponse)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Update(ctx, &UpdateOptions{ID: mockGroupID, Body: nil})
 		assertT := assert.New(t)
 
@@ -356,7 +356,7 @@ func TestGroupsServiceUpdate(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithExpiredTimeout)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Update(ctx, &UpdateOptions{ID: mockGroupID, Body: nil})
 		assertT := assert.New(t)
 
@@ -371,7 +371,7 @@ func TestGroupsServiceUpdate(t *testing.T) {
 func TestGroupServiceDelete(t *testing.T) {
 	t.Run("should delete the group when passing a valid token", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteDeletedGroup)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Delete(context.Background(), &DeleteOptions{ID: mockGroupID})
 		assertT := assert.New(t)
 
@@ -381,7 +381,7 @@ func TestGroupServiceDelete(t *testing.T) {
 
 	t.Run("should return an error when passing an empty token", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteDeletedGroup)
-		service := NewGroupService(mock, "")
+		service := NewGroupService(mock, api.NewStaticTokenSource(""))
 		ok, err := service.Delete(context.Background(), &DeleteOptions{ID: mockGroupID})
 		assertT := assert.New(t)
 
@@ -391,7 +391,7 @@ func TestGroupServiceDelete(t *testing.T) {
 
 	t.Run("should return an error when passing an empty group id", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithGroupNotFound)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Delete(context.Background(), &DeleteOptions{ID: mockGroupID})
 		assertT := assert.New(t)
 
@@ -402,7 +402,7 @@ func TestGroupServiceDelete(t *testing.T) {
 
 	t.Run("should delete the group when using a context with timeout", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteDeletedGroup)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
 		ok, err := service.Delete(ctx, &DeleteOptions{ID: mockGroupID})
@@ -415,7 +415,7 @@ func TestGroupServiceDelete(t *testing.T) {
 
 	t.Run("should delete the group when the context timeout exceed", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithExpiredTimeout)
-		service := NewGroupService(mock, "token")
+		service := NewGroupService(mock, api.NewStaticTokenSource("token"))
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
 		ok, err := service.Delete(ctx, &DeleteOptions{ID: mockGroupID})
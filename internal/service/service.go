@@ -1,6 +1,11 @@
 package service
 
-import "github.com/strongdm/scimsdk/internal/api"
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/strongdm/scimsdk/internal/api"
+)
 
 type CreateOptions struct {
 	Body       interface{}
@@ -8,54 +13,144 @@ type CreateOptions struct {
 }
 
 type ListOptions struct {
-	PageSize   int
-	Offset     int
-	Filter     string
-	BaseAPIURL string
+	PageSize           int
+	Offset             int
+	Filter             string
+	SortBy             string
+	SortOrder          string
+	Attributes         []string
+	ExcludedAttributes []string
+	Search             bool
+	BaseAPIURL         string
 }
 
 type FindOptions struct {
-	ID         string
-	BaseAPIURL string
+	ID                 string
+	Attributes         []string
+	ExcludedAttributes []string
+	IfNoneMatch        string
+	BaseAPIURL         string
 }
 
 type ReplaceOptions struct {
 	ID         string
 	Body       interface{}
+	IfMatch    string
 	BaseAPIURL string
 }
 
 type UpdateOptions struct {
 	ID         string
 	Body       interface{}
+	IfMatch    string
 	BaseAPIURL string
 }
 
 type DeleteOptions struct {
 	ID         string
+	IfMatch    string
 	BaseAPIURL string
 }
 
+type PatchRequest struct {
+	Schemas    []string                 `json:"schemas"`
+	Operations []*PatchOperationRequest `json:"Operations"`
+}
+
+type PatchOperationRequest struct {
+	OP    string          `json:"op"`
+	Path  string          `json:"path,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// metadataTimeLayouts are the layouts of the meta dates, which some servers
+// send without the time zone.
+var metadataTimeLayouts = []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"}
+
+// MetadataResponse is the meta attribute of the resources. The dates that
+// can't be parsed are left empty instead of failing the whole response.
+type MetadataResponse struct {
+	ResourceType string    `json:"resourceType"`
+	Location     string    `json:"location"`
+	Created      time.Time `json:"-"`
+	LastModified time.Time `json:"-"`
+	Version      string    `json:"version"`
+}
+
+func (response *MetadataResponse) UnmarshalJSON(body []byte) error {
+	type metadataResponse MetadataResponse
+	dates := struct {
+		*metadataResponse
+		Created      string `json:"created"`
+		LastModified string `json:"lastModified"`
+	}{metadataResponse: (*metadataResponse)(response)}
+	if err := json.Unmarshal(body, &dates); err != nil {
+		return err
+	}
+	response.Created = parseMetadataTime(dates.Created)
+	response.LastModified = parseMetadataTime(dates.LastModified)
+	return nil
+}
+
+// setMetadataVersion uses the ETag header as the version of the resource
+// when the server doesn't send it in meta.version.
+func setMetadataVersion(meta **MetadataResponse, response *api.Response) {
+	etag := response.Header.Get("ETag")
+	if etag == "" {
+		return
+	} else if *meta == nil {
+		*meta = &MetadataResponse{}
+	}
+	if (*meta).Version == "" {
+		(*meta).Version = etag
+	}
+}
+
+func parseMetadataTime(value string) time.Time {
+	for _, layout := range metadataTimeLayouts {
+		if date, err := time.Parse(layout, value); err == nil {
+			return date
+		}
+	}
+	return time.Time{}
+}
+
 func newAPICreateOptions(opts *CreateOptions) *api.CreateOptions {
 	return api.NewCreateOptions(opts.Body, opts.BaseAPIURL)
 }
 
 func newAPIListOptions(opts *ListOptions) *api.ListOptions {
-	return api.NewListOptions(opts.PageSize, opts.Offset, opts.Filter, opts.BaseAPIURL)
+	listOptions := api.NewListOptions(opts.PageSize, opts.Offset, opts.Filter, opts.BaseAPIURL)
+	listOptions.SortBy = opts.SortBy
+	listOptions.SortOrder = opts.SortOrder
+	listOptions.Attributes = opts.Attributes
+	listOptions.ExcludedAttributes = opts.ExcludedAttributes
+	listOptions.Search = opts.Search
+	return listOptions
 }
 
 func newAPIFindOptions(opts *FindOptions) *api.FindOptions {
-	return api.NewFindOptions(opts.ID, opts.BaseAPIURL)
+	findOptions := api.NewFindOptions(opts.ID, opts.BaseAPIURL)
+	findOptions.Attributes = opts.Attributes
+	findOptions.ExcludedAttributes = opts.ExcludedAttributes
+	findOptions.IfNoneMatch = opts.IfNoneMatch
+	return findOptions
 }
 
 func newAPIReplaceOptions(opts *ReplaceOptions) *api.ReplaceOptions {
-	return api.NewReplaceOptions(opts.ID, opts.Body, opts.BaseAPIURL)
+	replaceOptions := api.NewReplaceOptions(opts.ID, opts.Body, opts.BaseAPIURL)
+	replaceOptions.IfMatch = opts.IfMatch
+	return replaceOptions
 }
 
 func newAPIUpdateOptions(opts *UpdateOptions) *api.UpdateOptions {
-	return api.NewUpdateOptions(opts.ID, opts.Body, opts.BaseAPIURL)
+	updateOptions := api.NewUpdateOptions(opts.ID, opts.Body, opts.BaseAPIURL)
+	updateOptions.IfMatch = opts.IfMatch
+	return updateOptions
 }
 
 func newAPIDeleteOptions(opts *DeleteOptions) *api.DeleteOptions {
-	return api.NewDeleteOptions(opts.ID, opts.BaseAPIURL)
+	deleteOptions := api.NewDeleteOptions(opts.ID, opts.BaseAPIURL)
+	deleteOptions.IfMatch = opts.IfMatch
+	return deleteOptions
 }
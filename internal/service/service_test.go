@@ -0,0 +1,86 @@
+package service
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/strongdm/scimsdk/internal/api"
+)
+
+func TestMetadataResponse(t *testing.T) {
+	t.Run("should parse the meta dates and version", func(t *testing.T) {
+		response := &UserResponse{}
+		err := json.Unmarshal([]byte(`{
+			"id": "xxx",
+			"meta": {
+				"resourceType": "User",
+				"location": "https://example.com/v2/Users/xxx",
+				"created": "2010-01-23T04:56:22Z",
+				"lastModified": "2011-05-13T04:42:34.123+02:00",
+				"version": "W/\"a330bc54f0671c9\""
+			}
+		}`), response)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("User", response.Meta.ResourceType)
+		assertT.Equal("https://example.com/v2/Users/xxx", response.Meta.Location)
+		assertT.True(time.Date(2010, 1, 23, 4, 56, 22, 0, time.UTC).Equal(response.Meta.Created))
+		assertT.True(time.Date(2011, 5, 13, 2, 42, 34, 123000000, time.UTC).Equal(response.Meta.LastModified))
+		assertT.Equal(`W/"a330bc54f0671c9"`, response.Meta.Version)
+	})
+
+	t.Run("should parse the dates without time zone as utc", func(t *testing.T) {
+		response := &MetadataResponse{}
+		err := json.Unmarshal([]byte(`{"created": "2010-01-23T04:56:22.5"}`), response)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal(time.Date(2010, 1, 23, 4, 56, 22, 500000000, time.UTC), response.Created)
+		assertT.True(response.LastModified.IsZero())
+	})
+
+	t.Run("should leave the invalid dates empty", func(t *testing.T) {
+		response := &GroupResponse{}
+		err := json.Unmarshal([]byte(`{"id": "yyy", "meta": {"resourceType": "Group", "lastModified": "yesterday"}}`), response)
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		assertT.Equal("Group", response.Meta.ResourceType)
+		assertT.True(response.Meta.LastModified.IsZero())
+	})
+}
+
+func TestMetadataVersion(t *testing.T) {
+	newResponse := func(etag string) *api.Response {
+		header := http.Header{}
+		if etag != "" {
+			header.Set("ETag", etag)
+		}
+		return &api.Response{StatusCode: http.StatusOK, Header: header}
+	}
+
+	t.Run("should use the ETag header when meta.version is missing", func(t *testing.T) {
+		var meta *MetadataResponse
+		setMetadataVersion(&meta, newResponse(`W/"1"`))
+
+		assert.Equal(t, `W/"1"`, meta.Version)
+	})
+
+	t.Run("should keep meta.version when the server sends it", func(t *testing.T) {
+		meta := &MetadataResponse{Version: `W/"2"`}
+		setMetadataVersion(&meta, newResponse(`W/"1"`))
+
+		assert.Equal(t, `W/"2"`, meta.Version)
+	})
+
+	t.Run("should leave the metadata empty without ETag", func(t *testing.T) {
+		var meta *MetadataResponse
+		setMetadataVersion(&meta, newResponse(""))
+
+		assert.Nil(t, meta)
+	})
+}
@@ -16,30 +16,29 @@ type UserService interface {
 }
 
 type userServiceImpl struct {
-	client api.API
-	token  string
+	client      api.API
+	tokenSource api.TokenSource
 }
 
 const usersAPIPathname = "Users"
 
-func NewUserService(api api.API, token string) UserService {
-	return &userServiceImpl{api, token}
+func NewUserService(api api.API, tokenSource api.TokenSource) UserService {
+	return &userServiceImpl{api, tokenSource}
 }
 
 func (service *userServiceImpl) Create(ctx context.Context, opts *CreateOptions) (*UserResponse, error) {
-	response, err := service.client.Create(ctx, usersAPIPathname, service.token, newAPICreateOptions(opts))
+	userResponse := &UserResponse{}
+	response, err := service.client.Create(ctx, usersAPIPathname, service.tokenSource, newAPICreateOptions(opts), userResponse)
 	if err != nil {
 		return nil, err
 	}
-	return unmarshalUserResponse(response.Body)
+	setMetadataVersion(&userResponse.Meta, response)
+	return userResponse, nil
 }
 
 func (service *userServiceImpl) List(ctx context.Context, opts *ListOptions) ([]*UserResponse, bool, error) {
-	response, err := service.client.List(ctx, usersAPIPathname, service.token, newAPIListOptions(opts))
-	if err != nil {
-		return nil, false, err
-	}
-	userPageResponse, err := unmarshalUserPageResponse(response.Body)
+	userPageResponse := &UserPageResponse{}
+	_, err := service.client.List(ctx, usersAPIPathname, service.tokenSource, newAPIListOptions(opts), userPageResponse)
 	if err != nil {
 		return nil, false, err
 	}
@@ -47,28 +46,32 @@ func (service *userServiceImpl) List(ctx context.Context, opts *ListOptions) ([]
 }
 
 func (service *userServiceImpl) Find(ctx context.Context, opts *FindOptions) (*UserResponse, error) {
-	response, err := service.client.Find(ctx, usersAPIPathname, service.token, newAPIFindOptions(opts))
+	userResponse := &UserResponse{}
+	response, err := service.client.Find(ctx, usersAPIPathname, service.tokenSource, newAPIFindOptions(opts), userResponse)
 	if err != nil {
 		return nil, err
 	}
-	return unmarshalUserResponse(response.Body)
+	setMetadataVersion(&userResponse.Meta, response)
+	return userResponse, nil
 }
 
 func (service *userServiceImpl) Replace(ctx context.Context, opts *ReplaceOptions) (*UserResponse, error) {
-	response, err := service.client.Replace(ctx, usersAPIPathname, service.token, newAPIReplaceOptions(opts))
+	userResponse := &UserResponse{}
+	response, err := service.client.Replace(ctx, usersAPIPathname, service.tokenSource, newAPIReplaceOptions(opts), userResponse)
 	if err != nil {
 		return nil, err
 	}
-	return unmarshalUserResponse(response.Body)
+	setMetadataVersion(&userResponse.Meta, response)
+	return userResponse, nil
 }
 
 func (service *userServiceImpl) Update(ctx context.Context, opts *UpdateOptions) (bool, error) {
-	_, err := service.client.Update(ctx, usersAPIPathname, service.token, newAPIUpdateOptions(opts))
+	_, err := service.client.Update(ctx, usersAPIPathname, service.tokenSource, newAPIUpdateOptions(opts), nil)
 	return err == nil, err
 }
 
 func (service *userServiceImpl) Delete(ctx context.Context, opts *DeleteOptions) (bool, error) {
-	_, err := service.client.Delete(ctx, usersAPIPathname, service.token, newAPIDeleteOptions(opts))
+	_, err := service.client.Delete(ctx, usersAPIPathname, service.tokenSource, newAPIDeleteOptions(opts))
 	if err != nil {
 		return false, err
 	}
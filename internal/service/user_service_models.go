@@ -1,5 +1,7 @@
 package service
 
+import "encoding/json"
+
 type UserPageResponse struct {
 	Resources    []*UserResponse `json:"Resources"`
 	ItemsPerPage int             `json:"itemsPerPage"`
@@ -10,19 +12,79 @@ type UserPageResponse struct {
 
 type UserResponse struct {
 	ID          string                       `json:"id"`
+	ExternalID  string                       `json:"externalId"`
 	Active      bool                         `json:"active"`
 	DisplayName string                       `json:"displayName"`
-	Emails      []UserEmailResponse          `json:"emails"`
+	Emails      []UserAttributeResponse      `json:"emails"`
 	Groups      []UserGroupReferenceResponse `json:"groups"`
-	Name        UserNameResponse             `json:"name"`
+	Name        *UserNameResponse            `json:"name"`
 	Schemas     []string                     `json:"schemas"`
 	UserName    string                       `json:"userName"`
 	UserType    string                       `json:"userType"`
+	Meta        *MetadataResponse            `json:"meta"`
+	// the other core attributes of RFC 7643 section 4.1
+	NickName          string                  `json:"nickName"`
+	ProfileURL        string                  `json:"profileUrl"`
+	Title             string                  `json:"title"`
+	PreferredLanguage string                  `json:"preferredLanguage"`
+	Locale            string                  `json:"locale"`
+	Timezone          string                  `json:"timezone"`
+	PhoneNumbers      []UserAttributeResponse `json:"phoneNumbers"`
+	IMs               []UserAttributeResponse `json:"ims"`
+	Photos            []UserAttributeResponse `json:"photos"`
+	Addresses         []UserAddressResponse   `json:"addresses"`
+	Entitlements      []UserAttributeResponse `json:"entitlements"`
+	Roles             []UserAttributeResponse `json:"roles"`
+	X509Certificates  []UserAttributeResponse `json:"x509Certificates"`
+	// EnterpriseUser is the Enterprise User extension
+	EnterpriseUser *EnterpriseUserResponse `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"`
+	// Extensions are the attributes of the other schema extensions
+	Extensions map[string]json.RawMessage `json:"-"`
+}
+
+func (response *UserResponse) UnmarshalJSON(body []byte) error {
+	type userResponse UserResponse
+	if err := json.Unmarshal(body, (*userResponse)(response)); err != nil {
+		return err
+	}
+	extensions, err := unmarshalExtensions(body, coreUserSchema, enterpriseUserSchema)
+	response.Extensions = extensions
+	return err
+}
+
+type EnterpriseUserResponse struct {
+	EmployeeNumber string                         `json:"employeeNumber"`
+	CostCenter     string                         `json:"costCenter"`
+	Organization   string                         `json:"organization"`
+	Division       string                         `json:"division"`
+	Department     string                         `json:"department"`
+	Manager        *EnterpriseUserManagerResponse `json:"manager"`
+}
+
+type EnterpriseUserManagerResponse struct {
+	Value       string `json:"value"`
+	Ref         string `json:"$ref"`
+	DisplayName string `json:"displayName"`
 }
 
-type UserEmailResponse struct {
+// UserAttributeResponse is a value of the multi-valued attributes like
+// emails or phoneNumbers.
+type UserAttributeResponse struct {
 	Primary bool   `json:"primary"`
 	Value   string `json:"value"`
+	Type    string `json:"type"`
+	Display string `json:"display"`
+}
+
+type UserAddressResponse struct {
+	Primary       bool   `json:"primary"`
+	Formatted     string `json:"formatted"`
+	StreetAddress string `json:"streetAddress"`
+	Locality      string `json:"locality"`
+	Region        string `json:"region"`
+	PostalCode    string `json:"postalCode"`
+	Country       string `json:"country"`
+	Type          string `json:"type"`
 }
 
 type UserGroupReferenceResponse struct {
@@ -31,29 +93,107 @@ type UserGroupReferenceResponse struct {
 }
 
 type UserNameResponse struct {
-	FamilyName string `json:"familyName"`
-	Formatted  string `json:"formatted"`
-	GivenName  string `json:"givenName"`
+	FamilyName      string `json:"familyName"`
+	Formatted       string `json:"formatted"`
+	GivenName       string `json:"givenName"`
+	MiddleName      string `json:"middleName"`
+	HonorificPrefix string `json:"honorificPrefix"`
+	HonorificSuffix string `json:"honorificSuffix"`
 }
 
 type CreateUserRequest struct {
-	Schemas  []string        `json:"schemas"`
-	UserName string          `json:"userName"`
-	Name     UserNameRequest `json:"name"`
-	Active   bool            `json:"active"`
+	Schemas    []string        `json:"schemas"`
+	ExternalID string          `json:"externalId,omitempty"`
+	UserName   string          `json:"userName"`
+	Name       UserNameRequest `json:"name"`
+	Active     bool            `json:"active"`
+	UserAttributesRequest
+	EnterpriseUser *EnterpriseUserRequest `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Extensions     map[string]interface{} `json:"-"`
+}
+
+func (request *CreateUserRequest) MarshalJSON() ([]byte, error) {
+	type createUserRequest CreateUserRequest
+	return marshalWithExtensions((*createUserRequest)(request), request.Extensions)
 }
 
 type ReplaceUserRequest struct {
-	ID       string          `json:"id"`
-	Schemas  []string        `json:"schemas"`
-	UserName string          `json:"userName"`
-	Name     UserNameRequest `json:"name"`
-	Active   bool            `json:"active"`
+	ID         string          `json:"id"`
+	Schemas    []string        `json:"schemas"`
+	ExternalID string          `json:"externalId,omitempty"`
+	UserName   string          `json:"userName"`
+	Name       UserNameRequest `json:"name"`
+	Active     bool            `json:"active"`
+	UserAttributesRequest
+	EnterpriseUser *EnterpriseUserRequest `json:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User,omitempty"`
+	Extensions     map[string]interface{} `json:"-"`
+}
+
+func (request *ReplaceUserRequest) MarshalJSON() ([]byte, error) {
+	type replaceUserRequest ReplaceUserRequest
+	return marshalWithExtensions((*replaceUserRequest)(request), request.Extensions)
+}
+
+type EnterpriseUserRequest struct {
+	EmployeeNumber string                        `json:"employeeNumber,omitempty"`
+	CostCenter     string                        `json:"costCenter,omitempty"`
+	Organization   string                        `json:"organization,omitempty"`
+	Division       string                        `json:"division,omitempty"`
+	Department     string                        `json:"department,omitempty"`
+	Manager        *EnterpriseUserManagerRequest `json:"manager,omitempty"`
+}
+
+type EnterpriseUserManagerRequest struct {
+	Value string `json:"value"`
+}
+
+// UserAttributesRequest holds the optional core attributes shared by the
+// create and replace requests.
+type UserAttributesRequest struct {
+	DisplayName       string                 `json:"displayName,omitempty"`
+	NickName          string                 `json:"nickName,omitempty"`
+	ProfileURL        string                 `json:"profileUrl,omitempty"`
+	Title             string                 `json:"title,omitempty"`
+	UserType          string                 `json:"userType,omitempty"`
+	PreferredLanguage string                 `json:"preferredLanguage,omitempty"`
+	Locale            string                 `json:"locale,omitempty"`
+	Timezone          string                 `json:"timezone,omitempty"`
+	Password          string                 `json:"password,omitempty"`
+	Emails            []UserAttributeRequest `json:"emails,omitempty"`
+	PhoneNumbers      []UserAttributeRequest `json:"phoneNumbers,omitempty"`
+	IMs               []UserAttributeRequest `json:"ims,omitempty"`
+	Photos            []UserAttributeRequest `json:"photos,omitempty"`
+	Addresses         []UserAddressRequest   `json:"addresses,omitempty"`
+	Entitlements      []UserAttributeRequest `json:"entitlements,omitempty"`
+	Roles             []UserAttributeRequest `json:"roles,omitempty"`
+	X509Certificates  []UserAttributeRequest `json:"x509Certificates,omitempty"`
+}
+
+type UserAttributeRequest struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Display string `json:"display,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type UserAddressRequest struct {
+	Formatted     string `json:"formatted,omitempty"`
+	StreetAddress string `json:"streetAddress,omitempty"`
+	Locality      string `json:"locality,omitempty"`
+	Region        string `json:"region,omitempty"`
+	PostalCode    string `json:"postalCode,omitempty"`
+	Country       string `json:"country,omitempty"`
+	Type          string `json:"type,omitempty"`
+	Primary       bool   `json:"primary,omitempty"`
 }
 
 type UserNameRequest struct {
-	GivenName  string `json:"givenName"`
-	FamilyName string `json:"familyName"`
+	GivenName       string `json:"givenName"`
+	FamilyName      string `json:"familyName"`
+	MiddleName      string `json:"middleName,omitempty"`
+	HonorificPrefix string `json:"honorificPrefix,omitempty"`
+	HonorificSuffix string `json:"honorificSuffix,omitempty"`
+	Formatted       string `json:"formatted,omitempty"`
 }
 
 type UpdateUserRequest struct {
@@ -24,7 +24,7 @@ const (
 func TestUsersServiceCreate(t *testing.T) {
 	t.Run("should create a user when passing valid data", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserResponse)
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		user, err := service.Create(context.Background(), &CreateOptions{Body: nil})
 		assertT := assert.New(t)
 
@@ -34,7 +34,7 @@ func TestUsersServiceCreate(t *testing.T) {
 
 	t.Run("should return an error when creating an user without token", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserResponse)
-		service := NewUserService(mock, "")
+		service := NewUserService(mock, api.NewStaticTokenSource(""))
 		user, err := service.Create(context.Background(), &CreateOptions{Body: nil})
 		assertT := assert.New(t)
 
@@ -46,7 +46,7 @@ func TestUsersServiceCreate(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserResponse)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		user, err := service.Create(ctx, &CreateOptions{Body: nil})
 		assertT := assert.New(t)
 
@@ -59,7 +59,7 @@ func TestUsersServiceCreate(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithExpiredTimeout)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		user, err := service.Create(ctx, &CreateOptions{Body: nil})
 		assertT := assert.New(t)
 
@@ -74,7 +74,7 @@ func TestUsersServiceCreate(t *testing.T) {
 func TestUsersServiceList(t *testing.T) {
 	t.Run("should return a list of users when there's no pagination options", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserPageResponse)
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		users, haveNextPage, err := service.List(context.Background(), &ListOptions{})
 		assertT := assert.New(t)
 
@@ -86,7 +86,7 @@ func TestUsersServiceList(t *testing.T) {
 
 	t.Run("should return a list of users when the page size is equal or lesser than the users count", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserPageResponse)
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		users, haveNextPage, err := service.List(context.Background(), &ListOptions{PageSize: mockUsersPageSize})
 		assertT := assert.New(t)
 
@@ -98,7 +98,7 @@ func TestUsersServiceList(t *testing.T) {
 
 	t.Run("should return an error when passing an empty token", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserPageResponse)
-		service := NewUserService(mock, "")
+		service := NewUserService(mock, api.NewStaticTokenSource(""))
 		users, haveNextPage, err := service.List(context.Background(), &ListOptions{})
 		assertT := assert.New(t)
 
@@ -111,7 +111,7 @@ func TestUsersServiceList(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserPageResponse)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		users, haveNextPage, err := service.List(context.Background(), &ListOptions{})
 		assertT := assert.New(t)
 
@@ -126,7 +126,7 @@ func TestUsersServiceList(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithExpiredTimeout)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		users, haveNextPage, err := service.List(context.Background(), &ListOptions{})
 		assertT := assert.New(t)
 
@@ -140,7 +140,7 @@ func TestUsersServiceList(t *testing.T) {
 
 	t.Run("should return false in haveNextPage when the page size is greater than the users count", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserPageResponse)
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		_, haveNextPage, _ := service.List(context.Background(), &ListOptions{PageSize: 3})
 		assertT := assert.New(t)
 
@@ -149,7 +149,7 @@ func TestUsersServiceList(t *testing.T) {
 
 	t.Run("should return zero users when the offset is greater than the page size and the users count", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserPageResponse)
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		users, haveNextPage, err := service.List(context.Background(), &ListOptions{PageSize: mockUsersPageSize, Offset: 3})
 		assertT := assert.New(t)
 
@@ -162,7 +162,7 @@ func TestUsersServiceList(t *testing.T) {
 func TestUsersServiceFind(t *testing.T) {
 	t.Run("should return an user when passing a valid user id", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserResponse)
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		user, err := service.Find(context.Background(), &FindOptions{ID: mockUserID})
 		assertT := assert.New(t)
 
@@ -172,7 +172,7 @@ func TestUsersServiceFind(t *testing.T) {
 
 	t.Run("should return an error when passing an invalid token", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserResponse)
-		service := NewUserService(mock, "")
+		service := NewUserService(mock, api.NewStaticTokenSource(""))
 		user, err := service.Find(context.Background(), &FindOptions{ID: mockUserID})
 		assertT := assert.New(t)
 
@@ -182,7 +182,7 @@ func TestUsersServiceFind(t *testing.T) {
 
 	t.Run("should return an error when passing an invalid user id", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserNotFound)
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		user, err := service.Find(context.Background(), &FindOptions{ID: "yyy"})
 		assertT := assert.New(t)
 
@@ -195,7 +195,7 @@ func TestUsersServiceFind(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserResponse)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		user, err := service.Find(context.Background(), &FindOptions{ID: mockUserID})
 		assertT := assert.New(t)
 
@@ -208,7 +208,7 @@ func TestUsersServiceFind(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithExpiredTimeout)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		user, err := service.Find(context.Background(), &FindOptions{ID: mockUserID})
 		assertT := assert.New(t)
 
@@ -223,8 +223,8 @@ func TestUsersServiceFind(t *testing.T) {
 func TestUsersServiceReplace(t *testing.T) {
 	t.Run("should replace an user when passing a valid user id", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserResponse)
-		service := NewUserService(mock, "token")
-		user, err := service.Replace(context.Background(), &ReplaceOptions{mockUserID, nil, ""})
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
+		user, err := service.Replace(context.Background(), &ReplaceOptions{ID: mockUserID})
 		assertT := assert.New(t)
 
 		assertT.NotNil(user)
@@ -233,8 +233,8 @@ func TestUsersServiceReplace(t *testing.T) {
 
 	t.Run("should return an error when passing an invalid user id", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserNotFound)
-		service := NewUserService(mock, "token")
-		user, err := service.Replace(context.Background(), &ReplaceOptions{mockUserID, nil, ""})
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
+		user, err := service.Replace(context.Background(), &ReplaceOptions{ID: mockUserID})
 		assertT := assert.New(t)
 
 		assertT.Nil(user)
@@ -244,8 +244,8 @@ func TestUsersServiceReplace(t *testing.T) {
 
 	t.Run("should return an error when passing an empty token", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserResponse)
-		service := NewUserService(mock, "")
-		user, err := service.Replace(context.Background(), &ReplaceOptions{mockUserID, nil, ""})
+		service := NewUserService(mock, api.NewStaticTokenSource(""))
+		user, err := service.Replace(context.Background(), &ReplaceOptions{ID: mockUserID})
 		assertT := assert.New(t)
 
 		assertT.Nil(user)
@@ -256,7 +256,7 @@ func TestUsersServiceReplace(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserResponse)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		user, err := service.Replace(ctx, &ReplaceOptions{ID: mockUserID, Body: nil})
 		assertT := assert.New(t)
 
@@ -269,7 +269,7 @@ func TestUsersServiceReplace(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithExpiredTimeout)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		user, err := service.Replace(ctx, &ReplaceOptions{ID: mockUserID, Body: nil})
 		assertT := assert.New(t)
 
@@ -284,7 +284,7 @@ func TestUsersServiceReplace(t *testing.T) {
 func TestUsersServiceUpdate(t *testing.T) {
 	t.Run("should update an user when passing a valid id", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserResponse)
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Update(context.Background(), &UpdateOptions{ID: mockUserID, Body: nil})
 		assertT := assert.New(t)
 
@@ -294,7 +294,7 @@ func TestUsersServiceUpdate(t *testing.T) {
 
 	t.Run("should return an error when passing an invalid token", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserResponse)
-		service := NewUserService(mock, "")
+		service := NewUserService(mock, api.NewStaticTokenSource(""))
 		ok, err := service.Update(context.Background(), &UpdateOptions{ID: mockUserID, Body: nil})
 		assertT := assert.New(t)
 
@@ -304,7 +304,7 @@ func TestUsersServiceUpdate(t *testing.T) {
 
 	t.Run("should return an error when passing an empty user-id", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserNotFound)
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Update(context.Background(), &UpdateOptions{ID: "", Body: nil})
 		assertT := assert.New(t)
 
@@ -316,7 +316,7 @@ func TestUsersServiceUpdate(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserResponse)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Update(ctx, &UpdateOptions{ID: mockUserID, Body: nil})
 		assertT := assert.New(t)
 
@@ -329,7 +329,7 @@ func TestUsersServiceUpdate(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithExpiredTimeout)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Update(ctx, &UpdateOptions{ID: mockUserID, Body: nil})
 		assertT := assert.New(t)
 
@@ -344,7 +344,7 @@ func TestUsersServiceUpdate(t *testing.T) {
 func TestUsersServiceDelete(t *testing.T) {
 	t.Run("should delete the user when passing a valid user-id", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteDeletedUser)
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Delete(context.Background(), &DeleteOptions{ID: mockUserID})
 		assertT := assert.New(t)
 
@@ -354,7 +354,7 @@ func TestUsersServiceDelete(t *testing.T) {
 
 	t.Run("should return an error when passing an invalid token", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteDeletedUser)
-		service := NewUserService(mock, "")
+		service := NewUserService(mock, api.NewStaticTokenSource(""))
 		ok, err := service.Delete(context.Background(), &DeleteOptions{ID: mockUserID})
 		assertT := assert.New(t)
 
@@ -364,7 +364,7 @@ func TestUsersServiceDelete(t *testing.T) {
 
 	t.Run("should return an error when passing an invalid user-id", func(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithUserNotFound)
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Delete(context.Background(), &DeleteOptions{ID: "yyy"})
 		assertT := assert.New(t)
 
@@ -377,7 +377,7 @@ func TestUsersServiceDelete(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteDeletedUser)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Delete(ctx, &DeleteOptions{ID: mockUserID})
 		assertT := assert.New(t)
 
@@ -390,7 +390,7 @@ func TestUsersServiceDelete(t *testing.T) {
 		mock := api.NewMockAPI(mockedApiExecuteWithExpiredTimeout)
 		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
 		defer cancel()
-		service := NewUserService(mock, "token")
+		service := NewUserService(mock, api.NewStaticTokenSource("token"))
 		ok, err := service.Delete(ctx, &DeleteOptions{ID: mockUserID})
 		assertT := assert.New(t)
 
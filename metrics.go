@@ -0,0 +1,13 @@
+package scimsdk
+
+import "github.com/strongdm/scimsdk/internal/api"
+
+// Metrics records the SDK operation metrics. The operation is the module
+// method name (e.g. Users.Create) and statusCode is 0 when the request failed
+// without a response.
+type Metrics = api.Metrics
+
+// NewNopMetrics returns a Metrics implementation that records nothing.
+func NewNopMetrics() Metrics {
+	return api.NewNopMetrics()
+}
@@ -0,0 +1,88 @@
+// Package prometheus provides a scimsdk.Metrics implementation backed by the
+// Prometheus client, so every service using the SDK exposes the same metrics.
+package prometheus
+
+import (
+	"strconv"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+)
+
+const transportErrorStatus = "error"
+
+// Metrics records the SDK metrics in Prometheus collectors:
+//   - <namespace>_requests_total{operation, method, status}
+//   - <namespace>_request_duration_seconds{operation, method, status}
+//   - <namespace>_retries_total{operation, method}
+//   - <namespace>_pages_total{operation, result}
+//   - <namespace>_items_total{operation}
+type Metrics struct {
+	requests        *prom.CounterVec
+	requestDuration *prom.HistogramVec
+	retries         *prom.CounterVec
+	pages           *prom.CounterVec
+	items           *prom.CounterVec
+}
+
+// NewMetrics creates the collectors using the namespace as metrics prefix
+// (e.g. scim) and registers them in the registerer.
+func NewMetrics(registerer prom.Registerer, namespace string) (*Metrics, error) {
+	metrics := &Metrics{
+		requests: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "requests_total",
+			Help:      "SCIM http requests by operation, method and status.",
+		}, []string{"operation", "method", "status"}),
+		requestDuration: prom.NewHistogramVec(prom.HistogramOpts{
+			Namespace: namespace,
+			Name:      "request_duration_seconds",
+			Help:      "SCIM http request latencies by operation, method and status.",
+			Buckets:   prom.DefBuckets,
+		}, []string{"operation", "method", "status"}),
+		retries: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "retries_total",
+			Help:      "SCIM http requests retried by operation and method.",
+		}, []string{"operation", "method"}),
+		pages: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "pages_total",
+			Help:      "SCIM pages fetched by the iterators by operation and result.",
+		}, []string{"operation", "result"}),
+		items: prom.NewCounterVec(prom.CounterOpts{
+			Namespace: namespace,
+			Name:      "items_total",
+			Help:      "SCIM resources fetched by the iterators by operation.",
+		}, []string{"operation"}),
+	}
+	collectors := []prom.Collector{metrics.requests, metrics.requestDuration, metrics.retries, metrics.pages, metrics.items}
+	for _, collector := range collectors {
+		if err := registerer.Register(collector); err != nil {
+			return nil, err
+		}
+	}
+	return metrics, nil
+}
+
+func (metrics *Metrics) ObserveRequest(operation, method string, statusCode int, latency time.Duration) {
+	status := transportErrorStatus
+	if statusCode > 0 {
+		status = strconv.Itoa(statusCode)
+	}
+	metrics.requests.WithLabelValues(operation, method, status).Inc()
+	metrics.requestDuration.WithLabelValues(operation, method, status).Observe(latency.Seconds())
+}
+
+func (metrics *Metrics) IncRetries(operation, method string) {
+	metrics.retries.WithLabelValues(operation, method).Inc()
+}
+
+func (metrics *Metrics) ObservePage(operation string, items int, err error) {
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	metrics.pages.WithLabelValues(operation, result).Inc()
+	metrics.items.WithLabelValues(operation).Add(float64(items))
+}
@@ -0,0 +1,50 @@
+package prometheus
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	prom "github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/strongdm/scimsdk"
+)
+
+var _ scimsdk.Metrics = &Metrics{}
+
+func TestPrometheusMetrics(t *testing.T) {
+	t.Run("should record the requests by operation, method and status", func(t *testing.T) {
+		metrics, err := NewMetrics(prom.NewRegistry(), "scim")
+		assertT := assert.New(t)
+
+		assertT.Nil(err)
+		metrics.ObserveRequest("Users.Find", "GET", 200, time.Millisecond)
+		metrics.ObserveRequest("Users.Find", "GET", 200, time.Millisecond)
+		metrics.ObserveRequest("Users.Find", "GET", 0, time.Millisecond)
+		metrics.IncRetries("Users.Find", "GET")
+		assertT.Equal(float64(2), testutil.ToFloat64(metrics.requests.WithLabelValues("Users.Find", "GET", "200")))
+		assertT.Equal(float64(1), testutil.ToFloat64(metrics.requests.WithLabelValues("Users.Find", "GET", "error")))
+		assertT.Equal(float64(1), testutil.ToFloat64(metrics.retries.WithLabelValues("Users.Find", "GET")))
+		assertT.Equal(2, testutil.CollectAndCount(metrics.requestDuration))
+	})
+
+	t.Run("should record the pages and items fetched by the iterators", func(t *testing.T) {
+		metrics, _ := NewMetrics(prom.NewRegistry(), "scim")
+		metrics.ObservePage("Groups.List", 5, nil)
+		metrics.ObservePage("Groups.List", 0, errors.New("xxx"))
+		assertT := assert.New(t)
+
+		assertT.Equal(float64(1), testutil.ToFloat64(metrics.pages.WithLabelValues("Groups.List", "success")))
+		assertT.Equal(float64(1), testutil.ToFloat64(metrics.pages.WithLabelValues("Groups.List", "error")))
+		assertT.Equal(float64(5), testutil.ToFloat64(metrics.items.WithLabelValues("Groups.List")))
+	})
+
+	t.Run("should return an error when the metrics are already registered", func(t *testing.T) {
+		registry := prom.NewRegistry()
+		NewMetrics(registry, "scim")
+		_, err := NewMetrics(registry, "scim")
+
+		assert.NotNil(t, err)
+	})
+}
@@ -0,0 +1,19 @@
+package scimsdk
+
+import "github.com/strongdm/scimsdk/internal/api"
+
+// Operation describes the SDK operation that originated a request, e.g.
+// Users.Create or Groups.UpdateAddMembers, and the affected resource id.
+type Operation = api.Operation
+
+// RequestHandler executes an http request.
+type RequestHandler = api.RequestHandler
+
+// Middleware wraps every outgoing request and incoming response of the client.
+// It must call next to continue the chain, e.g.:
+//
+//	func(operation scimsdk.Operation, request *http.Request, next scimsdk.RequestHandler) (*http.Response, error) {
+//		request.Header.Set("X-Correlation-Id", newCorrelationID())
+//		return next(request)
+//	}
+type Middleware = api.Middleware
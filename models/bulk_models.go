@@ -0,0 +1,67 @@
+package models
+
+type BulkMethod string
+
+const (
+	BulkMethodPost   BulkMethod = "POST"
+	BulkMethodPut    BulkMethod = "PUT"
+	BulkMethodPatch  BulkMethod = "PATCH"
+	BulkMethodDelete BulkMethod = "DELETE"
+)
+
+type BulkResourceType string
+
+const (
+	BulkResourceUsers  BulkResourceType = "Users"
+	BulkResourceGroups BulkResourceType = "Groups"
+)
+
+type BulkRequest struct {
+	// FailOnErrors is the number of failed operations after which the
+	// remaining ones aren't executed. When it's 0, every operation is executed
+	FailOnErrors int
+	Operations   []*BulkOperation
+}
+
+type BulkOperation struct {
+	Method       BulkMethod
+	ResourceType BulkResourceType
+	// ID is the id of the replaced, patched or deleted resource. Use
+	// BulkIDRef to reference a resource created in the same request
+	ID string
+	// BulkID identifies the resource created by a POST operation, so the
+	// other operations can reference it. It's required by the POST operations
+	BulkID string
+	// Data is the operation body:
+	//   - POST: CreateUser or CreateGroupBody
+	//   - PUT: ReplaceUser or ReplaceGroupBody
+	//   - PATCH: UpdateUser, *UserPatch, UpdateGroupReplaceName, UpdateGroupAddMembers,
+	//     UpdateGroupReplaceMembers, UpdateGroupRemoveMembers or *GroupPatch
+	//   - DELETE: nil
+	Data interface{}
+}
+
+type BulkResponse struct {
+	// Operations are the results of the executed operations, in the request
+	// order. The operations skipped because of FailOnErrors are omitted
+	Operations []*BulkOperationResult
+}
+
+type BulkOperationResult struct {
+	Method BulkMethod
+	BulkID string
+	// ID is the id of the created or modified resource
+	ID       string
+	Location string
+	Version  string
+	// Status is the http status of the operation
+	Status int
+	// Error is the *scimsdk.Error of a failed operation
+	Error error
+}
+
+// BulkIDRef returns the reference to the resource created by the operation
+// with the bulkID, which can be used as an operation ID or a group member ID.
+func BulkIDRef(bulkID string) string {
+	return "bulkId:" + bulkID
+}
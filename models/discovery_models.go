@@ -0,0 +1,75 @@
+package models
+
+type ServiceProviderConfig struct {
+	DocumentationURI      string
+	Patch                 FeatureSupport
+	Bulk                  BulkSupport
+	Filter                FilterSupport
+	ChangePassword        FeatureSupport
+	Sort                  FeatureSupport
+	ETag                  FeatureSupport
+	AuthenticationSchemes []AuthenticationScheme
+}
+
+type FeatureSupport struct {
+	Supported bool
+}
+
+type BulkSupport struct {
+	Supported bool
+	// MaxOperations is the maximum number of operations in a bulk request
+	MaxOperations int
+	// MaxPayloadSize is the maximum size in bytes of a bulk request
+	MaxPayloadSize int
+}
+
+type FilterSupport struct {
+	Supported bool
+	// MaxResults is the maximum number of resources returned by a list
+	MaxResults int
+}
+
+type AuthenticationScheme struct {
+	Type             string
+	Name             string
+	Description      string
+	SpecURI          string
+	DocumentationURI string
+	Primary          bool
+}
+
+type ResourceType struct {
+	ID               string
+	Name             string
+	Description      string
+	Endpoint         string
+	Schema           string
+	SchemaExtensions []SchemaExtension
+}
+
+type SchemaExtension struct {
+	Schema   string
+	Required bool
+}
+
+type Schema struct {
+	ID          string
+	Name        string
+	Description string
+	Attributes  []*SchemaAttribute
+}
+
+type SchemaAttribute struct {
+	Name            string
+	Type            string
+	MultiValued     bool
+	Description     string
+	Required        bool
+	CanonicalValues []string
+	CaseExact       bool
+	Mutability      string
+	Returned        string
+	Uniqueness      string
+	ReferenceTypes  []string
+	SubAttributes   []*SchemaAttribute
+}
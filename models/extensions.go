@@ -0,0 +1,42 @@
+package models
+
+import (
+	"reflect"
+	"sync"
+)
+
+// Extensions holds the attributes of the schema extensions by their schema
+// URI. The values are encoded with encoding/json, so they can be maps of
+// attributes or structs with json tags. The extensions of the responses are
+// decoded into a pointer to the type registered with RegisterExtension, or
+// into a map[string]interface{} when no type is registered.
+type Extensions map[string]interface{}
+
+var extensionTypes = struct {
+	sync.RWMutex
+	types map[string]reflect.Type
+}{types: map[string]reflect.Type{}}
+
+// RegisterExtension registers the type the attributes of the schema extension
+// are decoded into, e.g. RegisterExtension("urn:example:badge", Badge{})
+// decodes them into a *Badge.
+func RegisterExtension(schema string, value interface{}) {
+	valueType := reflect.TypeOf(value)
+	for valueType.Kind() == reflect.Pointer {
+		valueType = valueType.Elem()
+	}
+	extensionTypes.Lock()
+	defer extensionTypes.Unlock()
+	extensionTypes.types[schema] = valueType
+}
+
+// NewExtension returns a pointer to a new value of the type registered for
+// the schema extension, or a new map when there's no registered type.
+func NewExtension(schema string) interface{} {
+	extensionTypes.RLock()
+	defer extensionTypes.RUnlock()
+	if valueType, ok := extensionTypes.types[schema]; ok {
+		return reflect.New(valueType).Interface()
+	}
+	return &map[string]interface{}{}
+}
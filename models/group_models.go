@@ -1,25 +1,33 @@
 package models
 
+import "github.com/strongdm/scimsdk/filter"
+
 type Group struct {
 	ID          string
+	ExternalID  string
 	DisplayName string
 	Members     []*GroupMember
-	Meta        *GroupMetadata
+	Meta        *Metadata
+	// Extensions holds the attributes of the schema extensions
+	Extensions Extensions `scim:",extensions"`
 }
 
 type GroupMember struct {
-	ID    string
-	Email string
+	ID    string `scim:"value"`
+	Email string `scim:"display"`
 }
 
-type GroupMetadata struct {
-	ResourceType string
-	Location     string
-}
+// GroupMetadata is the previous name of Metadata.
+type GroupMetadata = Metadata
 
 type CreateGroupBody struct {
+	// ExternalID is the id of the group in the provisioning client (e.g. the
+	// HR system), unique among its groups
+	ExternalID  string
 	DisplayName string
 	Members     []GroupMember
+	// Extensions adds the attributes and schemas of the schema extensions
+	Extensions Extensions
 }
 
 type ReplaceGroupBody CreateGroupBody
@@ -27,3 +35,89 @@ type ReplaceGroupBody CreateGroupBody
 type UpdateGroupReplaceName struct {
 	DisplayName string
 }
+
+type UpdateGroupAddMembers struct {
+	Members []GroupMember
+}
+
+type UpdateGroupReplaceMembers struct {
+	Members []GroupMember
+}
+
+type UpdateGroupRemoveMembers struct {
+	// Filter selects the removed members, e.g. filter.Eq("value", memberID)
+	Filter filter.Filter
+}
+
+// GroupPatch builds a PATCH request whose operations are applied atomically
+// by the server, e.g. to rename a group and swap its members at once:
+//
+//	patch := models.NewGroupPatch().
+//		ReplaceDisplayName("Engineering").
+//		AddMembers(models.GroupMember{ID: newMemberID}).
+//		RemoveMembersByID(oldMemberID)
+type GroupPatch struct {
+	Operations []PatchOperation
+}
+
+func NewGroupPatch() *GroupPatch {
+	return &GroupPatch{}
+}
+
+func (patch *GroupPatch) Add(path string, value interface{}) *GroupPatch {
+	patch.Operations = append(patch.Operations, PatchOperation{PatchAdd, path, value})
+	return patch
+}
+
+func (patch *GroupPatch) Replace(path string, value interface{}) *GroupPatch {
+	patch.Operations = append(patch.Operations, PatchOperation{PatchReplace, path, value})
+	return patch
+}
+
+func (patch *GroupPatch) Remove(path string) *GroupPatch {
+	patch.Operations = append(patch.Operations, PatchOperation{Op: PatchRemove, Path: path})
+	return patch
+}
+
+func (patch *GroupPatch) AddMembers(members ...GroupMember) *GroupPatch {
+	return patch.Add("members", members)
+}
+
+// ReplaceMembers replaces every member of the group, removing all of them
+// when no members are passed.
+func (patch *GroupPatch) ReplaceMembers(members ...GroupMember) *GroupPatch {
+	if members == nil {
+		members = []GroupMember{}
+	}
+	return patch.Replace("members", members)
+}
+
+// RemoveMembers removes the members matching the filter, e.g.
+// filter.Eq("value", memberID).
+func (patch *GroupPatch) RemoveMembers(membersFilter filter.Filter) *GroupPatch {
+	if membersFilter == nil {
+		return patch.Remove("")
+	}
+	return patch.Remove(filter.Values("members", membersFilter).String())
+}
+
+// RemoveMembersByID removes the members with the ids in one operation.
+func (patch *GroupPatch) RemoveMembersByID(memberIDs ...string) *GroupPatch {
+	membersFilters := []filter.Filter{}
+	for _, memberID := range memberIDs {
+		membersFilters = append(membersFilters, filter.Eq("value", memberID))
+	}
+	return patch.RemoveMembers(filter.Or(membersFilters...))
+}
+
+func (patch *GroupPatch) ReplaceDisplayName(displayName string) *GroupPatch {
+	return patch.Replace("displayName", displayName)
+}
+
+func (patch *GroupPatch) ReplaceExternalID(externalID string) *GroupPatch {
+	return patch.Replace("externalId", externalID)
+}
+
+func (patch *GroupPatch) RemoveExternalID() *GroupPatch {
+	return patch.Remove("externalId")
+}
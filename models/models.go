@@ -1,14 +1,101 @@
 package models
 
+import (
+	"time"
+
+	"github.com/strongdm/scimsdk/filter"
+)
+
+// Metadata is the meta attribute set by the server on the resources.
+type Metadata struct {
+	ResourceType string
+	Location     string
+	Created      time.Time
+	// LastModified is the last time the resource was changed, e.g. to filter
+	// the users modified since the last sync with filter.Gt("meta.lastModified", date)
+	LastModified time.Time
+	// Version is the entity tag of the resource, e.g. W/"3694e05e9dff590"
+	Version string
+}
+
 type PaginationOptions struct {
 	PageSize int
 	Offset   int
-	Filter   string
+	// Filter is a raw SCIM filter expression
+	Filter string
+	// FilterExpression is a filter built with the filter package, which
+	// escapes its values. It can't be used along with Filter
+	FilterExpression filter.Filter
+	// SortBy is the attribute path used to sort the resources (e.g. userName).
+	// When the server doesn't support sorting, the client fetches every
+	// resource matching the filter into memory to sort them before returning
+	// the first page, failing when there are more than the client
+	// MaxSortedResources
+	SortBy string
+	// SortOrder is the sort order (default: SortAscending)
+	SortOrder SortOrder
+	// Attributes are the only attributes returned by the server (e.g.
+	// id and displayName), so the other fields are left empty
+	Attributes []string
+	// ExcludedAttributes are the attributes omitted by the server (e.g.
+	// members). It can't be used along with Attributes
+	ExcludedAttributes []string
+	// Search sends the requests as a POST to the .search endpoint, keeping
+	// the filter out of the urls and the access logs. It's used automatically
+	// when the filter is too long to be sent in the url
+	Search bool
+}
+
+type FindOptions struct {
+	// Attributes are the only attributes returned by the server
+	Attributes []string
+	// ExcludedAttributes are the attributes omitted by the server. It can't
+	// be used along with Attributes
+	ExcludedAttributes []string
+	// IfNoneMatch is the version of a previously found resource (its
+	// Meta.Version). When it didn't change the server responds with 304 and
+	// an error matching ErrNotModified is returned
+	IfNoneMatch string
+}
+
+// WriteOptions are the optional settings of the requests changing a resource.
+type WriteOptions struct {
+	// IfMatch is the version of the resource (its Meta.Version) the change is
+	// based on. When another client changed the resource since then, the
+	// server responds with 412 and an error matching ErrPreconditionFailed
+	// is returned. Servers without versioning support ignore it
+	IfMatch string
 }
 
+type SortOrder string
+
+const (
+	SortAscending  SortOrder = "ascending"
+	SortDescending SortOrder = "descending"
+)
+
 type Iterator[T interface{}] interface {
 	Next() bool
 	Value() *T
 	Err() error
 	IsEmpty() bool
 }
+
+type PatchOp string
+
+const (
+	PatchAdd     PatchOp = "add"
+	PatchReplace PatchOp = "replace"
+	PatchRemove  PatchOp = "remove"
+)
+
+// PatchOperation is an operation of a PATCH request (RFC 7644 section 3.5.2).
+type PatchOperation struct {
+	Op PatchOp
+	// Path is the attribute path, e.g. name.givenName or
+	// emails[type eq "work"].value. It's required by the remove operations
+	Path string
+	// Value is the new attribute value, which can be a porcelain model (e.g.
+	// UserEmail), or the map of attributes when the path is empty
+	Value interface{}
+}
@@ -1,37 +1,144 @@
 package models
 
+// EnterpriseUserSchema is the schema URI of the Enterprise User extension.
+const EnterpriseUserSchema = "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"
+
 type User struct {
-	ID          string
-	Active      bool
-	DisplayName string
-	Emails      []UserEmail
-	Groups      []UserGroupReference
-	Name        *UserName
-	UserName    string
-	UserType    string
+	ID                string
+	ExternalID        string
+	Active            bool
+	DisplayName       string
+	Emails            []UserEmail
+	Groups            []UserGroupReference
+	Name              *UserName
+	UserName          string
+	UserType          string
+	Meta              *Metadata
+	NickName          string
+	ProfileURL        string `scim:"profileUrl"`
+	Title             string
+	PreferredLanguage string
+	Locale            string
+	Timezone          string
+	PhoneNumbers      []UserPhoneNumber
+	IMs               []UserAttribute `scim:"ims"`
+	Photos            []UserAttribute
+	Addresses         []UserAddress
+	Entitlements      []UserAttribute
+	Roles             []UserAttribute
+	X509Certificates  []UserAttribute
+	// EnterpriseUser holds the Enterprise User extension attributes, or nil
+	// when the server didn't return them
+	EnterpriseUser *EnterpriseUser `scim:"urn:ietf:params:scim:schemas:extension:enterprise:2.0:User"`
+	// Extensions holds the attributes of the other schema extensions
+	Extensions Extensions `scim:",extensions"`
 }
 
 type UserEmail struct {
 	Primary bool
 	Value   string
+	// Type is the kind of email, e.g. "work", "home" or "other"
+	Type    string
+	Display string
+}
+
+type UserPhoneNumber struct {
+	Primary bool
+	Value   string
+	// Type is the kind of phone number, e.g. "work", "home" or "mobile"
+	Type    string
+	Display string
+}
+
+type UserAddress struct {
+	Primary       bool
+	Formatted     string
+	StreetAddress string
+	Locality      string
+	Region        string
+	PostalCode    string
+	Country       string
+	// Type is the kind of address, e.g. "work", "home" or "other"
+	Type string
+}
+
+// UserAttribute is a value of the multi-valued attributes without
+// sub-attributes of their own: ims, photos, entitlements, roles and
+// x509Certificates.
+type UserAttribute struct {
+	Primary bool
+	Value   string
+	Type    string
+	Display string
 }
 
 type UserGroupReference struct {
 	Value string
-	Ref   string
+	Ref   string `scim:"$ref"`
 }
 
 type UserName struct {
-	FamilyName string
-	Formatted  string
-	GivenName  string
+	FamilyName      string
+	Formatted       string
+	GivenName       string
+	MiddleName      string
+	HonorificPrefix string
+	HonorificSuffix string
+}
+
+// EnterpriseUser is the Enterprise User extension (RFC 7643 section 4.3).
+type EnterpriseUser struct {
+	EmployeeNumber string
+	CostCenter     string
+	Organization   string
+	Division       string
+	Department     string
+	Manager        *EnterpriseUserManager
+}
+
+type EnterpriseUserManager struct {
+	// ID is the id of the manager user
+	ID          string `scim:"value"`
+	Ref         string `scim:"$ref"`
+	DisplayName string
 }
 
 type CreateUser struct {
-	UserName   string
-	GivenName  string
-	FamilyName string
-	Active     bool
+	// ExternalID is the identifier of the user in the provisioning client
+	ExternalID      string
+	UserName        string
+	GivenName       string
+	FamilyName      string
+	MiddleName      string
+	HonorificPrefix string
+	HonorificSuffix string
+	// FormattedName is the full name, e.g. "Ms. Barbara J Jensen, III"
+	FormattedName     string
+	Active            bool
+	DisplayName       string
+	NickName          string
+	ProfileURL        string
+	Title             string
+	UserType          string
+	PreferredLanguage string
+	Locale            string
+	Timezone          string
+	// Password is the initial password, never returned by the server
+	Password string
+	// Emails, PhoneNumbers, IMs, Photos, Addresses, Entitlements, Roles and
+	// X509Certificates accept at most one primary value each
+	Emails           []UserEmail
+	PhoneNumbers     []UserPhoneNumber
+	IMs              []UserAttribute
+	Photos           []UserAttribute
+	Addresses        []UserAddress
+	Entitlements     []UserAttribute
+	Roles            []UserAttribute
+	X509Certificates []UserAttribute
+	// EnterpriseUser adds the Enterprise User extension attributes and schema
+	EnterpriseUser *EnterpriseUser
+	// Extensions adds the attributes and schemas of other extensions
+	Extensions Extensions
 }
 
 type ReplaceUser CreateUser
@@ -39,3 +146,33 @@ type ReplaceUser CreateUser
 type UpdateUser struct {
 	Active bool
 }
+
+// UserPatch builds a PATCH request whose operations are applied atomically
+// by the server:
+//
+//	patch := models.NewUserPatch().
+//		Replace("name.givenName", "Jane").
+//		Replace(filter.Values("emails", filter.Eq("type", "work")).String()+".value", "jane@example.com").
+//		Remove("nickName")
+type UserPatch struct {
+	Operations []PatchOperation
+}
+
+func NewUserPatch() *UserPatch {
+	return &UserPatch{}
+}
+
+func (patch *UserPatch) Add(path string, value interface{}) *UserPatch {
+	patch.Operations = append(patch.Operations, PatchOperation{PatchAdd, path, value})
+	return patch
+}
+
+func (patch *UserPatch) Replace(path string, value interface{}) *UserPatch {
+	patch.Operations = append(patch.Operations, PatchOperation{PatchReplace, path, value})
+	return patch
+}
+
+func (patch *UserPatch) Remove(path string) *UserPatch {
+	patch.Operations = append(patch.Operations, PatchOperation{Op: PatchRemove, Path: path})
+	return patch
+}
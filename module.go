@@ -3,6 +3,7 @@ package scimsdk
 import (
 	"context"
 
+	"github.com/strongdm/scimsdk/filter"
 	"github.com/strongdm/scimsdk/models"
 )
 
@@ -10,19 +11,62 @@ type UserModule interface {
 	Create(context.Context, models.CreateUser) (*models.User, error)
 	List(context.Context, *models.PaginationOptions) models.Iterator[models.User]
 	Find(context.Context, string) (*models.User, error)
-	Replace(context.Context, string, models.ReplaceUser) (*models.User, error)
-	Update(context.Context, string, models.UpdateUser) (bool, error)
-	Delete(context.Context, string) (bool, error)
+	// FindWithOptions finds a user returning only the requested attributes
+	FindWithOptions(context.Context, string, *models.FindOptions) (*models.User, error)
+	// FindByExternalID finds the user with the externalId set by the
+	// provisioning client. It fails with an error matching ErrNotFound when
+	// there's no such user
+	FindByExternalID(context.Context, string) (*models.User, error)
+	// Replace, Update, Patch and Delete accept the WriteOptions to only change
+	// the user when it still has the IfMatch version
+	Replace(context.Context, string, models.ReplaceUser, ...*models.WriteOptions) (*models.User, error)
+	Update(context.Context, string, models.UpdateUser, ...*models.WriteOptions) (bool, error)
+	// Patch applies the operations built with models.NewUserPatch in a single
+	// request, leaving the other attributes untouched
+	Patch(context.Context, string, *models.UserPatch, ...*models.WriteOptions) (bool, error)
+	Delete(context.Context, string, ...*models.WriteOptions) (bool, error)
+}
+
+// BulkModule executes several operations in the Bulk requests of the server,
+// or one by one when the server doesn't support them. When a request fails
+// after some operations were executed, Execute returns their results along
+// with the error.
+type BulkModule interface {
+	Execute(context.Context, *models.BulkRequest) (*models.BulkResponse, error)
+}
+
+// DiscoveryModule describes what the server supports. The
+// ServiceProviderConfig is requested once and cached by the client.
+type DiscoveryModule interface {
+	ServiceProviderConfig(context.Context) (*models.ServiceProviderConfig, error)
+	ResourceTypes(context.Context) ([]*models.ResourceType, error)
+	Schemas(context.Context) ([]*models.Schema, error)
 }
 
 type GroupModule interface {
 	Create(context.Context, models.CreateGroupBody) (*models.Group, error)
 	List(context.Context, *models.PaginationOptions) models.Iterator[models.Group]
 	Find(context.Context, string) (*models.Group, error)
-	Replace(context.Context, string, models.ReplaceGroupBody) (*models.Group, error)
-	UpdateAddMembers(context.Context, string, []models.GroupMember) (bool, error)
-	UpdateReplaceMembers(context.Context, string, []models.GroupMember) (bool, error)
-	UpdateReplaceName(context.Context, string, models.UpdateGroupReplaceName) (bool, error)
-	UpdateRemoveMemberByID(context.Context, string, string) (bool, error)
-	Delete(context.Context, string) (bool, error)
+	// FindWithOptions finds a group returning only the requested attributes
+	// (e.g. without its members)
+	FindWithOptions(context.Context, string, *models.FindOptions) (*models.Group, error)
+	// FindByExternalID finds the group with the externalId set by the
+	// provisioning client. It fails with an error matching ErrNotFound when
+	// there's no such group
+	FindByExternalID(context.Context, string) (*models.Group, error)
+	// Replace, the Update methods, Patch and Delete accept the WriteOptions to
+	// only change the group when it still has the IfMatch version, e.g. so
+	// concurrent jobs don't overwrite each other's members
+	Replace(context.Context, string, models.ReplaceGroupBody, ...*models.WriteOptions) (*models.Group, error)
+	UpdateAddMembers(context.Context, string, []models.GroupMember, ...*models.WriteOptions) (bool, error)
+	UpdateReplaceMembers(context.Context, string, []models.GroupMember, ...*models.WriteOptions) (bool, error)
+	UpdateReplaceName(context.Context, string, models.UpdateGroupReplaceName, ...*models.WriteOptions) (bool, error)
+	UpdateRemoveMemberByID(context.Context, string, string, ...*models.WriteOptions) (bool, error)
+	// UpdateRemoveMembers removes the members matching the filter, e.g.
+	// filter.Eq("value", memberID)
+	UpdateRemoveMembers(context.Context, string, filter.Filter, ...*models.WriteOptions) (bool, error)
+	// Patch applies the operations built with models.NewGroupPatch in a
+	// single request and returns the updated group
+	Patch(context.Context, string, *models.GroupPatch, ...*models.WriteOptions) (*models.Group, error)
+	Delete(context.Context, string, ...*models.WriteOptions) (bool, error)
 }
@@ -0,0 +1,41 @@
+package scimsdk
+
+import "github.com/strongdm/scimsdk/internal/api"
+
+// TokenSource provides the token used to authenticate each request. Token is
+// called before every request and Refresh once when the server responds with
+// 401 Unauthorized, before failing.
+type TokenSource = api.TokenSource
+
+// ClientCredentialsConfig defines the OAuth2 client credentials grant used by
+// NewClientCredentialsTokenSource.
+type ClientCredentialsConfig = api.ClientCredentialsConfig
+
+// NewStaticTokenSource returns a token source that always uses the same token.
+func NewStaticTokenSource(token string) TokenSource {
+	return api.NewStaticTokenSource(token)
+}
+
+// NewEnvTokenSource returns a token source that reads the token from an
+// environment variable on every request.
+func NewEnvTokenSource(name string) TokenSource {
+	return api.NewEnvTokenSource(name)
+}
+
+// NewFileTokenSource returns a token source that reads the token from a file,
+// reloading it whenever the file changes.
+func NewFileTokenSource(path string) TokenSource {
+	return api.NewFileTokenSource(path)
+}
+
+// NewCommandTokenSource returns a token source that runs an external command
+// and uses its output as token, running it again when the token is rejected.
+func NewCommandTokenSource(name string, args ...string) TokenSource {
+	return api.NewCommandTokenSource(name, args...)
+}
+
+// NewClientCredentialsTokenSource returns a token source that requests the
+// token from an OAuth2 token endpoint, renewing it before it expires.
+func NewClientCredentialsTokenSource(config ClientCredentialsConfig) TokenSource {
+	return api.NewClientCredentialsTokenSource(config)
+}